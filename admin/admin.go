@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	ophttp "github.com/ethereum-optimism/optimism/op-service/httputil"
+	registry "github.com/ethereum-optimism/superchain-registry/superchain"
+	"github.com/ethereum-optimism/supersim/opsimulator"
+	"github.com/ethereum-optimism/supersim/orchestrator"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	host = "127.0.0.1"
+
+	// JSON-RPC namespace of the admin methods, i.e `supersim_snapshot`
+	Namespace = "supersim"
+)
+
+// AdminServer exposes control over the whole superchain managed by the Orchestrator
+type AdminServer struct {
+	log log.Logger
+
+	orchestrator *orchestrator.Orchestrator
+
+	port       uint64
+	rpcServer  *rpc.Server
+	httpServer *ophttp.HTTPServer
+
+	stopped atomic.Bool
+}
+
+type adminAPI struct {
+	log          log.Logger
+	orchestrator *orchestrator.Orchestrator
+}
+
+func NewAdminServer(log log.Logger, port uint64, orchestrator *orchestrator.Orchestrator) *AdminServer {
+	return &AdminServer{log: log, port: port, orchestrator: orchestrator}
+}
+
+func (s *AdminServer) Start(ctx context.Context) error {
+	s.rpcServer = rpc.NewServer()
+	if err := s.rpcServer.RegisterName(Namespace, &adminAPI{s.log, s.orchestrator}); err != nil {
+		return fmt.Errorf("failed to register admin api: %w", err)
+	}
+
+	hs, err := ophttp.StartHTTPServer(net.JoinHostPort(host, fmt.Sprintf("%d", s.port)), s.rpcServer)
+	if err != nil {
+		return fmt.Errorf("failed to start admin HTTP RPC server: %w", err)
+	}
+
+	s.log.Debug("started admin server", "addr", hs.Addr())
+	s.httpServer = hs
+
+	if s.port == 0 {
+		s.port, err = strconv.ParseUint(strings.Split(hs.Addr().String(), ":")[1], 10, 64)
+		if err != nil {
+			panic(fmt.Errorf("unexpected admin server listening port: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func (s *AdminServer) Stop(ctx context.Context) error {
+	if s.stopped.Load() {
+		return errors.New("already stopped")
+	}
+	if !s.stopped.CompareAndSwap(false, true) {
+		return nil // someone else stopped
+	}
+	if s.httpServer == nil {
+		return nil // never started
+	}
+
+	s.rpcServer.Stop()
+	return s.httpServer.Stop(ctx)
+}
+
+func (s *AdminServer) Stopped() bool {
+	return s.stopped.Load()
+}
+
+func (s *AdminServer) Endpoint() string {
+	return fmt.Sprintf("http://%s:%d", host, s.port)
+}
+
+// ChainInfo describes a chain and how to reach it. L2 endpoints are served by the op-simulator
+type ChainInfo struct {
+	Name        string `json:"name"`
+	ChainID     uint64 `json:"chainId"`
+	RPCUrl      string `json:"rpcUrl"`
+	WSUrl       string `json:"wsUrl"`
+	LogPath     string `json:"logPath"`
+	IsL2        bool   `json:"isL2"`
+	L1ChainID   uint64 `json:"l1ChainId,omitempty"`
+	AnvilRPCUrl string `json:"anvilRpcUrl"`
+
+	DependencySet []uint64 `json:"dependencySet,omitempty"`
+}
+
+type ChainHealth struct {
+	ChainID uint64 `json:"chainId"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Health struct {
+	Healthy bool          `json:"healthy"`
+	Chains  []ChainHealth `json:"chains"`
+}
+
+// Chains lists the L1 followed by the L2s in order of chain id
+func (api *adminAPI) Chains() []ChainInfo {
+	l1 := api.orchestrator.L1Chain()
+	chains := []ChainInfo{{
+		Name:        l1.Name(),
+		ChainID:     l1.ChainID(),
+		RPCUrl:      l1.Endpoint(),
+		WSUrl:       l1.WSEndpoint(),
+		LogPath:     l1.LogPath(),
+		AnvilRPCUrl: l1.Endpoint(),
+	}}
+
+	for _, opSim := range api.sortedOpSims() {
+		chains = append(chains, ChainInfo{
+			Name:          opSim.Name(),
+			ChainID:       opSim.ChainID(),
+			RPCUrl:        opSim.Endpoint(),
+			WSUrl:         opSim.WSEndpoint(),
+			LogPath:       opSim.LogPath(),
+			IsL2:          true,
+			L1ChainID:     opSim.L2Config.L1ChainID,
+			AnvilRPCUrl:   api.orchestrator.L2Chain(opSim.ChainID()).Endpoint(),
+			DependencySet: opSim.DependencySet(),
+		})
+	}
+
+	return chains
+}
+
+// Config returns the same summary of the orchestrator printed on startup
+func (api *adminAPI) Config() string {
+	return api.orchestrator.ConfigAsString()
+}
+
+// Health reports whether every chain is running and responding through its endpoint
+func (api *adminAPI) Health(ctx context.Context) Health {
+	health := Health{Healthy: true}
+	for _, chain := range api.Chains() {
+		chainHealth := ChainHealth{ChainID: chain.ChainID, Healthy: true}
+		if err := checkChainHealth(ctx, chain); err != nil {
+			chainHealth.Healthy = false
+			chainHealth.Error = err.Error()
+			health.Healthy = false
+		}
+		health.Chains = append(health.Chains, chainHealth)
+	}
+	return health
+}
+
+// L1Addresses returns the L1 contract addresses of the L2 chain
+func (api *adminAPI) L1Addresses(chainID hexutil.Uint64) (*registry.AddressList, error) {
+	opSim, ok := api.orchestrator.L2OpSims[uint64(chainID)]
+	if !ok {
+		return nil, fmt.Errorf("no l2 chain with chain id %d", chainID)
+	}
+	return opSim.L2Config.L1Addresses, nil
+}
+
+func (api *adminAPI) sortedOpSims() []*opsimulator.OpSimulator {
+	opSims := make([]*opsimulator.OpSimulator, 0, len(api.orchestrator.L2OpSims))
+	for _, opSim := range api.orchestrator.L2OpSims {
+		opSims = append(opSims, opSim)
+	}
+	sort.Slice(opSims, func(i, j int) bool { return opSims[i].ChainID() < opSims[j].ChainID() })
+	return opSims
+}
+
+func checkChainHealth(ctx context.Context, chain ChainInfo) error {
+	client, err := rpc.DialContext(ctx, chain.RPCUrl)
+	if err != nil {
+		return fmt.Errorf("failed to dial: %w", err)
+	}
+	defer client.Close()
+
+	var chainID hexutil.Uint64
+	if err := client.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+		return fmt.Errorf("failed to query chain id: %w", err)
+	}
+	if uint64(chainID) != chain.ChainID {
+		return fmt.Errorf("unexpected chain id %d", chainID)
+	}
+	return nil
+}
+
+// Snapshot captures the state of every chain, returning the id to revert to
+func (api *adminAPI) Snapshot(ctx context.Context) (hexutil.Uint64, error) {
+	id, err := api.orchestrator.Snapshot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(id), nil
+}
+
+// Revert restores every chain to the snapshot. Like `evm_revert`, false
+// is returned when the snapshot does not exist
+func (api *adminAPI) Revert(ctx context.Context, id hexutil.Uint64) (bool, error) {
+	err := api.orchestrator.Revert(ctx, uint64(id))
+	if errors.Is(err, orchestrator.ErrSnapshotNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddDependency adds the dependency chain to the dependency set of the L2 chain
+func (api *adminAPI) AddDependency(ctx context.Context, chainID, dependencyChainID hexutil.Uint64) error {
+	return api.orchestrator.AddDependency(ctx, uint64(chainID), uint64(dependencyChainID))
+}
+
+// RemoveDependency removes the dependency chain from the dependency set of the L2 chain. Executing
+// messages that reference the dependency chain are rejected thereafter
+func (api *adminAPI) RemoveDependency(ctx context.Context, chainID, dependencyChainID hexutil.Uint64) error {
+	return api.orchestrator.RemoveDependency(ctx, uint64(chainID), uint64(dependencyChainID))
+}
+
+// Mine produces blocks on the L1 and every L2 with aligned timestamps. Every chain must be started
+// with the `none` mining mode
+func (api *adminAPI) Mine(ctx context.Context, blocks hexutil.Uint64) error {
+	return api.orchestrator.Mine(ctx, uint64(blocks))
+}
+
+// Warp advances the clock of every chain by the seconds, keeping the chains in sync
+func (api *adminAPI) Warp(ctx context.Context, seconds hexutil.Uint64) error {
+	return api.orchestrator.Warp(ctx, uint64(seconds))
+}
+
+type DepositRelayStatus struct {
+	ChainID uint64 `json:"chainId"`
+	opsimulator.DepositRelayStatus
+}
+
+// DepositRelayStatus reports, for every L2 in order of chain id, how far behind the L1 head the deposits relayed to the chain are
+func (api *adminAPI) DepositRelayStatus() []DepositRelayStatus {
+	var statuses []DepositRelayStatus
+	for _, opSim := range api.sortedOpSims() {
+		statuses = append(statuses, DepositRelayStatus{ChainID: opSim.ChainID(), DepositRelayStatus: opSim.DepositRelayStatus()})
+	}
+	return statuses
+}
+
+// ReorgL1 replaces the latest blocks of the L1 with as many blocks re-including their transactions. The
+// L2 blocks deriving from the replaced blocks are rolled back, and the deposits of the new blocks are relayed
+func (api *adminAPI) ReorgL1(ctx context.Context, depth hexutil.Uint64) error {
+	return api.orchestrator.ReorgL1(ctx, uint64(depth))
+}
@@ -3,6 +3,7 @@ package anvil
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
@@ -32,8 +35,9 @@ const (
 )
 
 type Anvil struct {
+	// replaced on restart while read by other components
+	clientsMu sync.RWMutex
 	rpcClient *rpc.Client
-
 	ethClient *ethclient.Client
 
 	log         log.Logger
@@ -45,8 +49,20 @@ type Anvil struct {
 	resourceCtx    context.Context
 	resourceCancel context.CancelFunc
 
-	stopped   atomic.Bool
-	stoppedCh chan struct{}
+	// set once the state is dumped prior to anvil terminating
+	dumpMu sync.Mutex
+	dumped bool
+
+	stopped atomic.Bool
+
+	// closed once the running anvil process exits. Replaced on restart
+	exitMu   sync.Mutex
+	exitedCh chan struct{}
+	exitErr  error
+
+	// number of times the process was started. State not persisted with the
+	// dumps, such as snapshots, does not survive a restart
+	starts atomic.Uint64
 }
 
 func New(log log.Logger, cfg *config.ChainConfig) *Anvil {
@@ -56,7 +72,6 @@ func New(log log.Logger, cfg *config.ChainConfig) *Anvil {
 		cfg:            cfg,
 		resourceCtx:    resCtx,
 		resourceCancel: resCancel,
-		stoppedCh:      make(chan struct{}, 1),
 	}
 }
 
@@ -73,9 +88,20 @@ func (a *Anvil) Start(ctx context.Context) error {
 		"--chain-id", fmt.Sprintf("%d", a.cfg.ChainID),
 		"--port", fmt.Sprintf("%d", a.cfg.Port),
 		"--optimism",
-		"--block-time", "2",
 	}
 
+	// Deposits pay no fees and would otherwise be ordered last. Transactions are instead included in the
+	// order submitted, placing the deposits relayed for an L1 block ahead of later transactions. An L2 mined
+	// on an interval has its blocks sealed by the op-simulator, which seals pending transactions ahead of the deposits
+	miningArgs := a.cfg.MiningConfig.AnvilArgs()
+	if a.cfg.L2Config != nil {
+		args = append(args, "--order", "fifo")
+		if a.cfg.MiningConfig.MiningMode() == config.MiningModeInterval {
+			miningArgs = []string{"--no-mining"}
+		}
+	}
+	args = append(args, miningArgs...)
+
 	if len(a.cfg.GenesisJSON) > 0 && a.cfg.ForkConfig == nil {
 		tempFile, err := os.CreateTemp("", "genesis-*.json")
 		if err != nil {
@@ -96,10 +122,10 @@ func (a *Anvil) Start(ctx context.Context) error {
 	anvilLog.Debug("generated cmd arguments", "args", args)
 
 	a.cmd = exec.CommandContext(a.resourceCtx, "anvil", args...)
-	go func() {
-		<-ctx.Done()
-		a.resourceCancel()
-	}()
+	if a.cfg.StatePath != "" {
+		// anvil must outlive an interrupt of the terminal's process group for the state to be dumped
+		setProcessGroup(a.cmd)
+	}
 
 	// In the event anvil is started with port 0, we'll need to block
 	// and see what port anvil eventually binds to when started
@@ -152,14 +178,34 @@ func (a *Anvil) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to start anvil: %w", err)
 	}
 
+	exitedCh := make(chan struct{})
+	a.exitMu.Lock()
+	a.exitedCh, a.exitErr = exitedCh, nil
+	a.exitMu.Unlock()
+
+	cmd := a.cmd
 	go func() {
-		if err := a.cmd.Wait(); err != nil {
+		err := cmd.Wait()
+		if err != nil {
 			anvilLog.Error("anvil terminated with an error", "error", err)
 		} else {
 			anvilLog.Debug("anvil terminated")
 		}
 
-		a.stoppedCh <- struct{}{}
+		a.exitMu.Lock()
+		a.exitErr = err
+		a.exitMu.Unlock()
+		close(exitedCh)
+	}()
+
+	// bound to this process, such that a restart does not accumulate watchers
+	go func() {
+		select {
+		case <-ctx.Done():
+			a.dumpState()
+			a.resourceCancel()
+		case <-exitedCh:
+		}
 	}()
 
 	// wait & update the port. Since we're in the same routine to which `Start` is called,
@@ -172,12 +218,22 @@ func (a *Anvil) Start(ctx context.Context) error {
 		return ctx.Err()
 	}
 
-	rpcClient, err := rpc.Dial(a.wsEndpoint())
+	rpcClient, err := rpc.Dial(a.WSEndpoint())
 	if err != nil {
 		return fmt.Errorf("failed to create RPC client: %w", err)
 	}
+	a.clientsMu.Lock()
 	a.rpcClient = rpcClient
 	a.ethClient = ethclient.NewClient(rpcClient)
+	a.clientsMu.Unlock()
+
+	if a.cfg.StatePath != "" {
+		if err := a.loadState(ctx); err != nil {
+			return fmt.Errorf("failed to load state: %w", err)
+		}
+	}
+
+	a.starts.Add(1)
 	return nil
 }
 
@@ -189,21 +245,139 @@ func (a *Anvil) Stop() error {
 		return nil // someone else stopped
 	}
 
-	a.rpcClient.Close()
+	a.dumpState()
+	a.client().Close()
 	a.resourceCancel()
-	<-a.stoppedCh
+	<-a.Exited()
+	return nil
+}
+
+// Restart starts anvil again after it terminated unexpectedly, binding to the same port. If
+// configured, the chain state is loaded from the last dump
+func (a *Anvil) Restart(ctx context.Context) error {
+	if a.stopped.Load() {
+		return errors.New("anvil stopped")
+	}
+	if !a.Crashed() {
+		return errors.New("anvil is still running")
+	}
+
+	a.client().Close()
+	a.cmd = nil
+	return a.Start(ctx)
+}
+
+// Starts returns the number of times the anvil process was started
+func (a *Anvil) Starts() uint64 {
+	return a.starts.Load()
+}
+
+// Exited is closed once the anvil process terminates, expectedly or not
+func (a *Anvil) Exited() <-chan struct{} {
+	a.exitMu.Lock()
+	defer a.exitMu.Unlock()
+	return a.exitedCh
+}
+
+// Crashed reports if the anvil process terminated without being stopped
+func (a *Anvil) Crashed() bool {
+	if a.stopped.Load() {
+		return false
+	}
+
+	select {
+	case <-a.Exited():
+		return true
+	default:
+		return false
+	}
+}
+
+// ExitErr is the error the anvil process terminated with, if any
+func (a *Anvil) ExitErr() error {
+	a.exitMu.Lock()
+	defer a.exitMu.Unlock()
+	return a.exitErr
+}
+
+// loadState restores the state previously dumped to the configured
+// state path. No state is loaded if the file does not exist yet
+func (a *Anvil) loadState(ctx context.Context) error {
+	state, err := os.ReadFile(a.cfg.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	loaded, err := a.AnvilLoadState(ctx, state)
+	if err != nil {
+		return err
+	}
+	if !loaded {
+		return fmt.Errorf("anvil rejected the state in %s", a.cfg.StatePath)
+	}
+
+	a.log.Info("loaded chain state", "chain.id", a.cfg.ChainID, "path", a.cfg.StatePath)
+	return nil
+}
+
+// DumpState writes the chain state to the configured state path. The file is replaced
+// atomically so that a crash mid-write does not corrupt the previous dump
+func (a *Anvil) DumpState(ctx context.Context) error {
+	if a.cfg.StatePath == "" {
+		return errors.New("no state path configured")
+	}
+
+	state, err := a.AnvilDumpState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to dump chain state: %w", err)
+	}
+
+	tmpPath := a.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmpPath, state, 0o644); err != nil {
+		return fmt.Errorf("failed to write chain state: %w", err)
+	}
+	if err := os.Rename(tmpPath, a.cfg.StatePath); err != nil {
+		return fmt.Errorf("failed to replace chain state: %w", err)
+	}
 	return nil
 }
 
+// dumpState dumps the chain state, once, prior to anvil terminating. A failed
+// dump is attempted again by the next caller
+func (a *Anvil) dumpState() {
+	if a.cfg.StatePath == "" || a.client() == nil {
+		return
+	}
+
+	a.dumpMu.Lock()
+	defer a.dumpMu.Unlock()
+	if a.dumped {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := a.DumpState(ctx); err != nil {
+		a.log.Error("failed to dump chain state", "chain.id", a.cfg.ChainID, "path", a.cfg.StatePath, "err", err)
+		return
+	}
+
+	a.dumped = true
+	a.log.Info("dumped chain state", "chain.id", a.cfg.ChainID, "path", a.cfg.StatePath)
+}
+
 func (a *Anvil) Stopped() bool {
-	return a.stopped.Load()
+	return a.stopped.Load() || a.Crashed()
 }
 
 func (a *Anvil) Endpoint() string {
 	return fmt.Sprintf("http://%s:%d", host, a.cfg.Port)
 }
 
-func (a *Anvil) wsEndpoint() string {
+func (a *Anvil) WSEndpoint() string {
 	return fmt.Sprintf("ws://%s:%d", host, a.cfg.Port)
 }
 
@@ -254,18 +428,27 @@ func (a *Anvil) WaitUntilReady(ctx context.Context) error {
 
 func (a *Anvil) String() string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "Name: %s    Chain ID: %d    RPC: %s    LogPath: %s", a.Name(), a.ChainID(), a.Endpoint(), a.LogPath())
+	fmt.Fprintf(&b, "Name: %s    Chain ID: %d    RPC: %s    Mining: %s    LogPath: %s", a.Name(), a.ChainID(), a.Endpoint(), a.cfg.MiningConfig.String(), a.LogPath())
 	return b.String()
 }
 
 func (a *Anvil) EthClient() *ethclient.Client {
+	a.clientsMu.RLock()
+	defer a.clientsMu.RUnlock()
 	return a.ethClient
 }
 
+// client returns the rpc client of the running anvil process
+func (a *Anvil) client() *rpc.Client {
+	a.clientsMu.RLock()
+	defer a.clientsMu.RUnlock()
+	return a.rpcClient
+}
+
 // web3_ API
 func (a *Anvil) Web3ClientVersion(ctx context.Context) (string, error) {
 	var result string
-	if err := a.rpcClient.CallContext(ctx, &result, "web3_clientVersion"); err != nil {
+	if err := a.client().CallContext(ctx, &result, "web3_clientVersion"); err != nil {
 		return "", err
 	}
 	return result, nil
@@ -273,29 +456,124 @@ func (a *Anvil) Web3ClientVersion(ctx context.Context) (string, error) {
 
 // eth_ API
 func (a *Anvil) EthGetCode(ctx context.Context, account common.Address) ([]byte, error) {
-	return a.ethClient.CodeAt(ctx, account, nil)
+	return a.EthClient().CodeAt(ctx, account, nil)
 }
 
 func (a *Anvil) EthGetLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
-	return a.ethClient.FilterLogs(ctx, q)
+	return a.EthClient().FilterLogs(ctx, q)
 }
 
 func (a *Anvil) EthSendTransaction(ctx context.Context, tx *types.Transaction) error {
-	return a.ethClient.SendTransaction(ctx, tx)
+	return a.EthClient().SendTransaction(ctx, tx)
 }
 
 func (a *Anvil) EthBlockByNumber(ctx context.Context, blockHeight *big.Int) (*types.Block, error) {
-	return a.ethClient.BlockByNumber(ctx, blockHeight)
+	return a.EthClient().BlockByNumber(ctx, blockHeight)
+}
+
+// anvil_ API
+func (a *Anvil) AnvilDumpState(ctx context.Context) (hexutil.Bytes, error) {
+	var result hexutil.Bytes
+	if err := a.client().CallContext(ctx, &result, "anvil_dumpState"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *Anvil) AnvilLoadState(ctx context.Context, state hexutil.Bytes) (bool, error) {
+	var result bool
+	if err := a.client().CallContext(ctx, &result, "anvil_loadState", state); err != nil {
+		return false, err
+	}
+	return result, nil
+}
+
+// evm_ API
+func (a *Anvil) EvmSnapshot(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	if err := a.client().CallContext(ctx, &result, "evm_snapshot"); err != nil {
+		return nil, err
+	}
+	return result.ToInt(), nil
+}
+
+func (a *Anvil) EvmRevert(ctx context.Context, id *big.Int) (bool, error) {
+	var result bool
+	if err := a.client().CallContext(ctx, &result, "evm_revert", (*hexutil.Big)(id)); err != nil {
+		return false, err
+	}
+	return result, nil
+}
+
+func (a *Anvil) EvmSetNextBlockTimestamp(ctx context.Context, timestamp uint64) error {
+	return a.client().CallContext(ctx, nil, "evm_setNextBlockTimestamp", hexutil.Uint64(timestamp))
+}
+
+// EvmIncreaseTime advances the clock of the chain, affecting the timestamp of the following blocks
+func (a *Anvil) EvmIncreaseTime(ctx context.Context, seconds uint64) error {
+	return a.client().CallContext(ctx, nil, "evm_increaseTime", hexutil.Uint64(seconds))
+}
+
+func (a *Anvil) EvmMine(ctx context.Context) error {
+	return a.client().CallContext(ctx, nil, "evm_mine")
+}
+
+// AnvilSetCode replaces the code of the account
+func (a *Anvil) AnvilSetCode(ctx context.Context, account common.Address, code []byte) error {
+	return a.client().CallContext(ctx, nil, "anvil_setCode", account, hexutil.Bytes(code))
+}
+
+func (a *Anvil) AnvilSetStorageAt(ctx context.Context, account common.Address, slot, value common.Hash) error {
+	return a.client().CallContext(ctx, nil, "anvil_setStorageAt", account, slot, value)
+}
+
+type reorgOptions struct {
+	Depth        uint64    `json:"depth"`
+	TxBlockPairs []ReorgTx `json:"tx_block_pairs"`
+}
+
+// ReorgTx is a transaction included in one of the blocks replacing a reorged chain,
+// the block indexed from the first replaced block
+type ReorgTx struct {
+	Tx    *types.Transaction
+	Block uint64
+}
+
+func (r ReorgTx) MarshalJSON() ([]byte, error) {
+	raw, err := r.Tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal([]any{hexutil.Bytes(raw), r.Block})
+}
+
+// AnvilReorg replaces the latest blocks of the chain with as many blocks including the given
+// transactions. Other transactions of the replaced blocks are dropped
+func (a *Anvil) AnvilReorg(ctx context.Context, depth uint64, txs []ReorgTx) error {
+	if txs == nil {
+		txs = []ReorgTx{}
+	}
+	return a.client().CallContext(ctx, nil, "anvil_reorg", reorgOptions{Depth: depth, TxBlockPairs: txs})
+}
+
+// AnvilRollback removes the latest blocks of the chain, without mining replacements
+func (a *Anvil) AnvilRollback(ctx context.Context, depth uint64) error {
+	return a.client().CallContext(ctx, nil, "anvil_rollback", depth)
+}
+
+// AnvilDropTransaction removes the transaction from the pool, if pending
+func (a *Anvil) AnvilDropTransaction(ctx context.Context, hash common.Hash) error {
+	return a.client().CallContext(ctx, nil, "anvil_dropTransaction", hash)
 }
 
 // subscription API
 func (a *Anvil) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
-	return a.ethClient.SubscribeFilterLogs(ctx, q, ch)
+	return a.EthClient().SubscribeFilterLogs(ctx, q, ch)
 }
 
 func (a *Anvil) DebugTraceCall(ctx context.Context, txArgs config.TransactionArgs) (config.TraceCallRaw, error) {
 	var result config.TraceCallRaw
-	if err := a.rpcClient.CallContext(ctx, &result, "debug_traceCall", txArgs, "latest", map[string]interface{}{
+	if err := a.client().CallContext(ctx, &result, "debug_traceCall", txArgs, "latest", map[string]interface{}{
 		"tracer": "callTracer",
 		"tracerConfig": map[string]interface{}{
 			"withLog": true,
@@ -2,11 +2,15 @@ package anvil
 
 import (
 	"context"
+	"math/big"
+	"path/filepath"
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-service/testlog"
 	"github.com/ethereum-optimism/supersim/config"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -32,3 +36,33 @@ func TestAnvil(t *testing.T) {
 	require.NoError(t, client.CallContext(context.Background(), &chainId, "eth_chainId"))
 	require.Equal(t, uint64(chainId), cfg.ChainID)
 }
+
+func TestAnvilRestart(t *testing.T) {
+	cfg := config.ChainConfig{ChainID: 10, Port: 0, StatePath: filepath.Join(t.TempDir(), "state")}
+	testlog := testlog.Logger(t, log.LevelInfo)
+	anvil := New(testlog, &cfg)
+
+	require.NoError(t, anvil.Start(context.Background()))
+	defer func() { require.NoError(t, anvil.Stop()) }()
+
+	account := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	require.NoError(t, anvil.rpcClient.CallContext(context.Background(), nil, "anvil_setBalance", account, (*hexutil.Big)(big.NewInt(1))))
+	require.NoError(t, anvil.DumpState(context.Background()))
+
+	// terminate the process without stopping
+	require.NoError(t, anvil.cmd.Process.Kill())
+	<-anvil.Exited()
+	require.True(t, anvil.Crashed())
+	require.True(t, anvil.Stopped())
+
+	port := cfg.Port
+	require.NoError(t, anvil.Restart(context.Background()))
+	require.NoError(t, anvil.WaitUntilReady(context.Background()))
+	require.False(t, anvil.Crashed())
+	require.Equal(t, port, cfg.Port)
+
+	// restored from the last dump
+	balance, err := anvil.EthClient().BalanceAt(context.Background(), account, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), balance.Int64())
+}
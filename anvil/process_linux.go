@@ -0,0 +1,15 @@
+//go:build linux
+
+package anvil
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group, detaching it from
+// signals delivered to the foreground process group such as a terminal interrupt.
+// Anvil is still terminated should supersim crash or be killed without stopping it
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGTERM}
+}
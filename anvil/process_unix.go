@@ -0,0 +1,14 @@
+//go:build !windows && !linux
+
+package anvil
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group, detaching it from
+// signals delivered to the foreground process group such as a terminal interrupt
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
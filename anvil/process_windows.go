@@ -0,0 +1,9 @@
+//go:build windows
+
+package anvil
+
+import "os/exec"
+
+// setProcessGroup is a no-op. The console interrupt reaches anvil as well, in which
+// case the state is only dumped if the rpc server has not yet shut down
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -86,6 +86,15 @@ func SupersimMain(ctx *cli.Context, closeApp context.CancelCauseFunc) (cliapp.Li
 		return nil, fmt.Errorf("failed to create supersim: %w", err)
 	}
 
+	// shutdown if a chain terminates and is not restarted
+	go func() {
+		select {
+		case err := <-s.Orchestrator.Err():
+			closeApp(err)
+		case <-ctx.Context.Done():
+		}
+	}()
+
 	return s, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"slices"
 	"strings"
 
 	registry "github.com/ethereum-optimism/superchain-registry/superchain"
@@ -18,6 +19,9 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+// Message expiry window of the interop specification, 180 days
+const DefaultMessageExpiryWindow uint64 = 180 * 24 * 60 * 60
+
 var (
 	DefaultSecretsConfig = SecretsConfig{
 		Accounts:       10,
@@ -62,6 +66,9 @@ var (
 type ForkConfig struct {
 	RPCUrl      string
 	BlockNumber uint64
+
+	// Set the interop predeploys on the forked chain, which may not have activated interop
+	InteropPredeploys bool
 }
 
 type SecretsConfig struct {
@@ -70,10 +77,37 @@ type SecretsConfig struct {
 	DerivationPath accounts.DerivationPath
 }
 
+func (c *SecretsConfig) equal(other *SecretsConfig) bool {
+	return c.Accounts == other.Accounts && c.Mnemonic == other.Mnemonic && slices.Equal(c.DerivationPath, other.DerivationPath)
+}
+
 type L2Config struct {
 	L1ChainID     uint64
 	L1Addresses   *registry.AddressList
 	DependencySet []uint64
+
+	// Optional port for the op-simulator fronting the chain. When
+	// zero, the port is incremented from the network's L2StartingPort
+	Port uint64
+
+	// Optional positions the op-simulator resumes relaying from, restored from a previous session
+	RelayCursors *RelayCursors
+}
+
+// RelayCursors are the positions of the deposit and message relays of an op-simulator
+type RelayCursors struct {
+	// Last L1 block whose deposits were submitted, and the L2 head prior to them
+	L1Block     uint64      `json:"l1Block"`
+	L1BlockHash common.Hash `json:"l1BlockHash"`
+	L2Block     uint64      `json:"l2Block"`
+
+	// Next sent message to relay, by source chain id
+	Messages map[uint64]LogPosition `json:"messages,omitempty"`
+}
+
+type LogPosition struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	LogIndex    uint   `json:"logIndex"`
 }
 
 type ChainConfig struct {
@@ -87,15 +121,183 @@ type ChainConfig struct {
 	// Optional Config
 	ForkConfig *ForkConfig
 
+	// How blocks are produced, mined on an interval of DefaultBlockTime when unset
+	MiningConfig MiningConfig
+
+	// Optional file the chain state is loaded from when started and dumped to when stopped
+	StatePath string
+
 	// Optional Config (L1 chain if nil)
 	L2Config *L2Config
 }
 
+type InteropConfig struct {
+	// Automatically relay L2ToL2CrossDomainMessenger messages to their destination chain
+	AutoRelay bool
+
+	// Index of the account, derived from the chain's SecretsConfig, used to submit relay transactions
+	RelayerAccountIndex uint32
+
+	// Seconds after which an initiating message can no longer be executed. When
+	// zero, DefaultMessageExpiryWindow is used
+	MessageExpiryWindow uint64
+}
+
+type WithdrawalConfig struct {
+	// Automatically prove and finalize withdrawals initiated on the L2s
+	AutoFinalize bool
+
+	// Seconds to wait between proving and finalizing a withdrawal. When zero, the clocks of
+	// every chain are instead warped past the portal's proof maturity and dispute game finality delays
+	FinalizationDelay uint64
+
+	// Index of the account, derived from the L1's SecretsConfig, used to submit withdrawal transactions
+	AccountIndex uint32
+}
+
+type SafetyConfig struct {
+	// Report the L2 blocks derived from the safe and finalized L1 blocks as the safe and finalized
+	// heads, like an op-node following the L1. The lags are not applicable when set
+	FollowL1 bool
+
+	// Blocks the safe head lags behind the latest L2 block. When zero, the latest block is safe
+	SafeLag uint64
+
+	// Blocks the finalized head lags behind the latest L2 block. When zero, the latest block is finalized
+	FinalizedLag uint64
+}
+
+type SupervisorConfig struct {
+	// Restart anvil instances that terminate unexpectedly, loading the last dumped state if configured
+	RestartOnCrash bool
+
+	// Seconds between dumps of the chain states when a state path is configured. Zero
+	// disables periodic dumps, leaving only the dump on shutdown
+	StateDumpInterval uint64
+}
+
 type NetworkConfig struct {
 	L1Config ChainConfig
 
 	L2StartingPort uint64
 	L2Configs      []ChainConfig
+
+	InteropConfig    InteropConfig
+	WithdrawalConfig WithdrawalConfig
+	SafetyConfig     SafetyConfig
+	SupervisorConfig SupervisorConfig
+}
+
+// ReservedAccounts returns the prefunded accounts of the chain, by index, that supersim submits transactions
+// from. Using them for other transactions risks nonce collisions
+func (c *NetworkConfig) ReservedAccounts(cfg *ChainConfig) map[uint64]string {
+	reserved := make(map[uint64]string)
+	if cfg.L2Config != nil && c.InteropConfig.AutoRelay {
+		reserved[uint64(c.InteropConfig.RelayerAccountIndex)] = "l2 interop relayer"
+	}
+	if cfg.L2Config == nil && c.WithdrawalConfig.AutoFinalize {
+		reserved[uint64(c.WithdrawalConfig.AccountIndex)] = "l1 withdrawal finalizer"
+	}
+	return reserved
+}
+
+// L2Ports returns the port of the op-simulator fronting each L2 chain. Chains without an explicit port are
+// assigned incrementing ports from the L2StartingPort, or any available port when it is zero
+func (c *NetworkConfig) L2Ports() []uint64 {
+	ports := make([]uint64, len(c.L2Configs))
+	nextPort := c.L2StartingPort
+	for i, cfg := range c.L2Configs {
+		if cfg.L2Config != nil && cfg.L2Config.Port > 0 {
+			ports[i] = cfg.L2Config.Port
+			continue
+		}
+
+		ports[i] = nextPort
+		if nextPort > 0 {
+			nextPort++
+		}
+	}
+	return ports
+}
+
+// Check validates the network topology and the accounts used by supersim
+func (c *NetworkConfig) Check() error {
+	if c.L1Config.L2Config != nil {
+		return fmt.Errorf("l1 chain %s cannot have an l2 config", c.L1Config.Name)
+	}
+	if len(c.L2Configs) == 0 {
+		return fmt.Errorf("at least one l2 chain must be configured")
+	}
+
+	if err := c.L1Config.MiningConfig.Check(); err != nil {
+		return fmt.Errorf("invalid mining config of chain %s: %w", c.L1Config.Name, err)
+	}
+
+	names := map[string]bool{c.L1Config.Name: true}
+	chainIDs := map[uint64]bool{c.L1Config.ChainID: true}
+	ports := map[uint64]string{}
+	if c.L1Config.Port > 0 {
+		ports[c.L1Config.Port] = c.L1Config.Name
+	}
+
+	l2Ports := c.L2Ports()
+	for i, cfg := range c.L2Configs {
+		if cfg.Name == "" {
+			return fmt.Errorf("l2 chain %d must have a name", cfg.ChainID)
+		}
+		if names[cfg.Name] {
+			return fmt.Errorf("duplicate chain name: %s", cfg.Name)
+		}
+		if chainIDs[cfg.ChainID] {
+			return fmt.Errorf("duplicate chain id: %d", cfg.ChainID)
+		}
+		names[cfg.Name] = true
+		chainIDs[cfg.ChainID] = true
+
+		if cfg.L2Config == nil {
+			return fmt.Errorf("l2 chain %s is missing an l2 config", cfg.Name)
+		}
+		if err := cfg.MiningConfig.Check(); err != nil {
+			return fmt.Errorf("invalid mining config of chain %s: %w", cfg.Name, err)
+		}
+		if cfg.L2Config.L1ChainID != c.L1Config.ChainID {
+			return fmt.Errorf("l2 chain %s settles to unknown l1 chain id %d", cfg.Name, cfg.L2Config.L1ChainID)
+		}
+		if port := l2Ports[i]; port > 0 {
+			if name, ok := ports[port]; ok {
+				return fmt.Errorf("port %d is used by both %s and %s", port, name, cfg.Name)
+			}
+			ports[port] = cfg.Name
+		}
+
+		if c.InteropConfig.AutoRelay && uint64(c.InteropConfig.RelayerAccountIndex) >= cfg.SecretsConfig.Accounts {
+			return fmt.Errorf("relayer account index %d exceeds the number of prefunded accounts of chain %s (%d)",
+				c.InteropConfig.RelayerAccountIndex, cfg.Name, cfg.SecretsConfig.Accounts)
+		}
+	}
+
+	if c.WithdrawalConfig.AutoFinalize && uint64(c.WithdrawalConfig.AccountIndex) >= c.L1Config.SecretsConfig.Accounts {
+		return fmt.Errorf("withdrawals account index %d exceeds the number of prefunded accounts of chain %s (%d)",
+			c.WithdrawalConfig.AccountIndex, c.L1Config.Name, c.L1Config.SecretsConfig.Accounts)
+	}
+
+	for _, cfg := range c.L2Configs {
+		dependencies := map[uint64]bool{}
+		for _, chainID := range cfg.L2Config.DependencySet {
+			if chainID == cfg.ChainID {
+				return fmt.Errorf("l2 chain %s cannot depend on itself", cfg.Name)
+			}
+			if chainID == c.L1Config.ChainID || !chainIDs[chainID] {
+				return fmt.Errorf("dependency %d of l2 chain %s is not a configured l2 chain", chainID, cfg.Name)
+			}
+			if dependencies[chainID] {
+				return fmt.Errorf("duplicate dependency %d for l2 chain %s", chainID, cfg.Name)
+			}
+			dependencies[chainID] = true
+		}
+	}
+
+	return nil
 }
 
 type TransactionArgs struct {
@@ -130,6 +332,7 @@ type TraceCallRawLog struct {
 type Chain interface {
 	Name() string
 	Endpoint() string
+	WSEndpoint() string
 	ChainID() uint64
 	LogPath() string
 	Config() *ChainConfig
@@ -146,27 +349,74 @@ type Chain interface {
 	DebugTraceCall(ctx context.Context, txArgs TransactionArgs) (TraceCallRaw, error)
 }
 
-// Note: The default secrets config is used everywhere
-func DefaultSecretsConfigAsString() string {
-	hdAccountStore, err := hdaccount.NewHdAccountStore(DefaultSecretsConfig.Mnemonic, DefaultSecretsConfig.DerivationPath)
+// SecretsAsString lists the prefunded accounts of every chain. Chains sharing the same secrets are listed
+// together, annotated with the chains when they differ. Reserved accounts are annotated with their use
+func (c *NetworkConfig) SecretsAsString() string {
+	type secretsGroup struct {
+		secrets  SecretsConfig
+		chains   []string
+		reserved map[uint64]string
+	}
+
+	var groups []*secretsGroup
+	for _, cfg := range append([]ChainConfig{c.L1Config}, c.L2Configs...) {
+		idx := slices.IndexFunc(groups, func(g *secretsGroup) bool { return g.secrets.equal(&cfg.SecretsConfig) })
+		if idx < 0 {
+			groups = append(groups, &secretsGroup{secrets: cfg.SecretsConfig, reserved: make(map[uint64]string)})
+			idx = len(groups) - 1
+		}
+
+		group := groups[idx]
+		group.chains = append(group.chains, cfg.Name)
+		for index, use := range c.ReservedAccounts(&cfg) {
+			if existing, ok := group.reserved[index]; !ok {
+				group.reserved[index] = use
+			} else if !strings.Contains(existing, use) {
+				group.reserved[index] = existing + ", " + use
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, group := range groups {
+		var chains []string
+		if len(groups) > 1 {
+			chains = group.chains
+		}
+		fmt.Fprint(&b, SecretsConfigAsString(&group.secrets, chains, group.reserved))
+	}
+	return b.String()
+}
+
+// SecretsConfigAsString lists the prefunded accounts of the secrets, noting the chains using them if any
+func SecretsConfigAsString(secrets *SecretsConfig, chains []string, reserved map[uint64]string) string {
+	hdAccountStore, err := hdaccount.NewHdAccountStore(secrets.Mnemonic, secrets.DerivationPath)
 	if err != nil {
 		panic(err)
 	}
 
 	var b strings.Builder
 
-	fmt.Fprintf(&b, "\nAvailable Accounts\n")
+	if len(chains) > 0 {
+		fmt.Fprintf(&b, "\nAvailable Accounts (%s)\n", strings.Join(chains, ", "))
+	} else {
+		fmt.Fprintf(&b, "\nAvailable Accounts\n")
+	}
 	fmt.Fprintf(&b, "-----------------------\n")
 
-	for i := range DefaultSecretsConfig.Accounts {
+	for i := range secrets.Accounts {
 		addressHex, _ := hdAccountStore.AddressHexAt(uint32(i))
-		fmt.Fprintf(&b, "(%d): %s\n", i, addressHex)
+		if use, ok := reserved[i]; ok {
+			fmt.Fprintf(&b, "(%d): %s (reserved: %s)\n", i, addressHex, use)
+		} else {
+			fmt.Fprintf(&b, "(%d): %s\n", i, addressHex)
+		}
 	}
 
 	fmt.Fprintf(&b, "\nPrivate Keys\n")
 	fmt.Fprintf(&b, "-----------------------\n")
 
-	for i := range DefaultSecretsConfig.Accounts {
+	for i := range secrets.Accounts {
 		privateKeyHex, _ := hdAccountStore.PrivateKeyHexAt(uint32(i))
 		fmt.Fprintf(&b, "(%d): %s\n", i, privateKeyHex)
 	}
@@ -2,28 +2,100 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	opservice "github.com/ethereum-optimism/optimism/op-service"
 
-	registry "github.com/ethereum-optimism/superchain-registry/superchain"
-
 	"github.com/urfave/cli/v2"
 )
 
 const (
 	ForkCommandName = "fork"
 
-	L1ForkHeightFlagName = "l1.fork.height"
-	L1PortFlagName       = "l1.port"
+	ConfigFlagName = "config"
+
+	AdminPortFlagName      = "admin.port"
+	SupervisorPortFlagName = "supervisor.port"
+	StateDirFlagName       = "state-dir"
+
+	StateIntervalFlagName = "state-interval"
+	AnvilRestartFlagName  = "anvil.restart"
+
+	L1ForkHeightFlagName   = "l1.fork.height"
+	ForkTimestampFlagName  = "fork.timestamp"
+	L2ForkHeightsFlagName  = "fork.height"
+	ForkCacheFlagName      = "fork.cache"
+	ForkOfflineFlagName    = "fork.offline"
+	ForkInteropFlagName    = "fork.interop"
+	ForkRegistryFlagName   = "fork.registry"
+	ForkChainsFileFlagName = "fork.chains.file"
+	L1PortFlagName         = "l1.port"
 
 	ChainsFlagName         = "chains"
 	NetworkFlagName        = "network"
 	L2StartingPortFlagName = "l2.starting.port"
+
+	MiningFlagName      = "mining"
+	L1BlockTimeFlagName = "l1.block.time"
+	L2BlockTimeFlagName = "l2.block.time"
+
+	InteropAutoRelayFlagName      = "interop.autorelay"
+	InteropRelayerAccountFlagName = "interop.relayer.account"
+	InteropExpiryWindowFlagName   = "interop.expiry.window"
+
+	WithdrawalsAutoFinalizeFlagName = "withdrawals.autofinalize"
+	WithdrawalsDelayFlagName        = "withdrawals.delay"
+	WithdrawalsAccountFlagName      = "withdrawals.account"
+
+	L2SafetyFollowL1FlagName = "l2.safety.l1"
+	L2SafeLagFlagName        = "l2.safe.lag"
+	L2FinalizedLagFlagName   = "l2.finalized.lag"
 )
 
+// L2ForkHeightFlagName is the flag overriding the fork height of the chain
+func L2ForkHeightFlagName(chain string) string {
+	return "fork.height." + chain
+}
+
 func BaseCLIFlags(envPrefix string) []cli.Flag {
 	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    ConfigFlagName,
+			Usage:   "Path to a TOML file declaring the L1 and L2 chains to run. Ports set in the file take precedence over the port flags",
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "CONFIG"),
+		},
+		&cli.StringFlag{
+			Name:    StateDirFlagName,
+			Usage:   "Directory the state of every chain is dumped to on shutdown and restored from on startup",
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "STATE_DIR"),
+		},
+		&cli.Uint64Flag{
+			Name:    StateIntervalFlagName,
+			Usage:   "Interval, in seconds, to dump the state of every chain to the state directory. `0` only dumps on shutdown",
+			Value:   0,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "STATE_INTERVAL"),
+		},
+		&cli.BoolFlag{
+			Name:    AnvilRestartFlagName,
+			Usage:   "Restart anvil instances that terminate unexpectedly, from the last dumped state if a state directory is set",
+			Value:   false,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "ANVIL_RESTART"),
+		},
+		&cli.Uint64Flag{
+			Name:    AdminPortFlagName,
+			Usage:   "Listening port for the supersim admin JSON-RPC server. `0` binds to any available port",
+			Value:   8420,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "ADMIN_PORT"),
+		},
+		&cli.Uint64Flag{
+			Name:    SupervisorPortFlagName,
+			Usage:   "Listening port for the op-supervisor compatible JSON-RPC server validating interop messages. `0` binds to any available port",
+			Value:   8421,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "SUPERVISOR_PORT"),
+		},
 		&cli.Uint64Flag{
 			Name:    L1PortFlagName,
 			Usage:   "Listening port for the L1 instance. `0` binds to any available port",
@@ -36,19 +108,103 @@ func BaseCLIFlags(envPrefix string) []cli.Flag {
 			Value:   9545,
 			EnvVars: opservice.PrefixEnvVar(envPrefix, "L2_STARTING_PORT"),
 		},
+		&cli.StringFlag{
+			Name:    MiningFlagName,
+			Usage:   "How every chain produces blocks. `interval` mines on the block time, `auto` mines a block per transaction and `none` only mines on request",
+			Value:   string(MiningModeInterval),
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "MINING"),
+		},
+		&cli.DurationFlag{
+			Name:    L1BlockTimeFlagName,
+			Usage:   "Block time of the L1 chain when mined on an interval, i.e `12s`",
+			Value:   DefaultBlockTime,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "L1_BLOCK_TIME"),
+		},
+		&cli.DurationFlag{
+			Name:    L2BlockTimeFlagName,
+			Usage:   "Block time of the L2 chains when mined on an interval, i.e `500ms`",
+			Value:   DefaultBlockTime,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "L2_BLOCK_TIME"),
+		},
+		&cli.BoolFlag{
+			Name:    InteropAutoRelayFlagName,
+			Usage:   "Automatically relay messages sent via the L2ToL2CrossDomainMessenger to the destination chain",
+			Value:   false,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "INTEROP_AUTORELAY"),
+		},
+		&cli.Uint64Flag{
+			Name:    InteropRelayerAccountFlagName,
+			Usage:   "Index of the prefunded account used to submit relayed messages when auto-relaying is enabled. The account is reserved for the relayer, as other transactions from it collide on nonces",
+			Value:   uint64(DefaultSecretsConfig.Accounts - 1),
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "INTEROP_RELAYER_ACCOUNT"),
+		},
+		&cli.Uint64Flag{
+			Name:    InteropExpiryWindowFlagName,
+			Usage:   "Seconds after which an initiating message can no longer be executed",
+			Value:   DefaultMessageExpiryWindow,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "INTEROP_EXPIRY_WINDOW"),
+		},
+		&cli.BoolFlag{
+			Name:    WithdrawalsAutoFinalizeFlagName,
+			Usage:   "Automatically propose, prove and finalize withdrawals initiated on the L2 chains",
+			Value:   false,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "WITHDRAWALS_AUTOFINALIZE"),
+		},
+		&cli.Uint64Flag{
+			Name:    WithdrawalsDelayFlagName,
+			Usage:   "Seconds to wait between proving and finalizing a withdrawal. `0` warps every chain to finalize instantly",
+			Value:   0,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "WITHDRAWALS_DELAY"),
+		},
+		&cli.Uint64Flag{
+			Name:    WithdrawalsAccountFlagName,
+			Usage:   "Index of the prefunded account used to submit withdrawal transactions on the L1",
+			Value:   uint64(DefaultSecretsConfig.Accounts - 2),
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "WITHDRAWALS_ACCOUNT"),
+		},
+		&cli.BoolFlag{
+			Name:    L2SafetyFollowL1FlagName,
+			Usage:   "Report the L2 blocks derived from the safe and finalized L1 blocks as the `safe` and `finalized` L2 blocks",
+			Value:   false,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "L2_SAFETY_L1"),
+		},
+		&cli.Uint64Flag{
+			Name:    L2SafeLagFlagName,
+			Usage:   "Blocks the `safe` L2 block lags behind the latest. `0` reports the latest block as safe",
+			Value:   0,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "L2_SAFE_LAG"),
+		},
+		&cli.Uint64Flag{
+			Name:    L2FinalizedLagFlagName,
+			Usage:   "Blocks the `finalized` L2 block lags behind the latest. `0` reports the latest block as finalized",
+			Value:   0,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "L2_FINALIZED_LAG"),
+		},
 	}
 }
 
 func ForkCLIFlags(envPrefix string) []cli.Flag {
-	networks := strings.Join(superchainNetworks(), ", ")
-	mainnetMembers := strings.Join(superchainMemberChains(registry.Superchains["mainnet"]), ", ")
-	return []cli.Flag{
+	networks := strings.Join(DefaultRegistry.Networks(), ", ")
+	mainnet, _ := DefaultRegistry.Network("mainnet")
+	mainnetMembers := strings.Join(mainnet.ChainNames(), ", ")
+	flags := []cli.Flag{
 		&cli.Uint64Flag{
 			Name:    L1ForkHeightFlagName,
 			Usage:   "L1 height to fork the superchain (bounds L2 time). `0` for latest",
 			Value:   0,
 			EnvVars: opservice.PrefixEnvVar(envPrefix, "L1_FORK_HEIGHT"),
 		},
+		&cli.Uint64Flag{
+			Name:    ForkTimestampFlagName,
+			Usage:   "Timestamp to fork the superchain at, resolving the L1 and L2 heights at that time. `0` to use the fork heights",
+			Value:   0,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "FORK_TIMESTAMP"),
+		},
+		&cli.StringSliceFlag{
+			Name:    L2ForkHeightsFlagName,
+			Usage:   "L2 heights to fork at as `<chain>=<height>`, for chains outside of the compiled registry. See --fork.height.<chain>",
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "FORK_HEIGHT"),
+		},
 		&cli.StringSliceFlag{
 			Name:     ChainsFlagName,
 			Usage:    fmt.Sprintf("chains to fork in the superchain, mainnet options: [%s]. In order to replace the public rpc endpoint for a chain, specify the ($%s_RPC_URL_<CHAIN>) env variable. i.e SUPERSIM_RPC_URL_OP=http://optimism-mainnet.infura.io/v3/<API-KEY>", mainnetMembers, envPrefix),
@@ -61,26 +217,140 @@ func ForkCLIFlags(envPrefix string) []cli.Flag {
 			Usage:   fmt.Sprintf("superchain network. options: %s. In order to replace the public rpc endpoint for the network, specify the ($%s_RPC_URL_<NETWORK>) env variable. i.e SUPERSIM_RPC_URL_MAINNET=http://mainnet.infura.io/v3/<API-KEY>", networks, envPrefix),
 			EnvVars: opservice.PrefixEnvVar(envPrefix, "NETWORK"),
 		},
+		&cli.StringFlag{
+			Name:    ForkCacheFlagName,
+			Usage:   "Directory recording the responses of the forked networks, replayed with --" + ForkOfflineFlagName,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "FORK_CACHE"),
+		},
+		&cli.BoolFlag{
+			Name:    ForkOfflineFlagName,
+			Usage:   "Serve the forked networks only from the responses recorded in the fork cache, without network access",
+			Value:   false,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "FORK_OFFLINE"),
+		},
+		&cli.BoolFlag{
+			Name:    ForkInteropFlagName,
+			Usage:   "Set the interop predeploys on every forked chain and add the forked chains to each other's dependency sets",
+			Value:   false,
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "FORK_INTEROP"),
+		},
+		&cli.StringFlag{
+			Name:    ForkRegistryFlagName,
+			Usage:   "Local superchain registry directory, laid out like the registry's `superchain/configs`. Its networks replace the compiled networks of the same name",
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "FORK_REGISTRY"),
+		},
+		&cli.StringFlag{
+			Name:    ForkChainsFileFlagName,
+			Usage:   "TOML file declaring chains outside of the registry (rpc url, chain id, block time, l1 addresses), added to the forked network",
+			EnvVars: opservice.PrefixEnvVar(envPrefix, "FORK_CHAINS_FILE"),
+		},
+	}
+
+	// overrides of the L2 height aligned with the L1 fork height, for every chain in the registry
+	for _, chain := range registryChainNames() {
+		envName := "FORK_HEIGHT_" + strings.ToUpper(strings.ReplaceAll(chain, "-", "_"))
+		flags = append(flags, &cli.Uint64Flag{
+			Name:     L2ForkHeightFlagName(chain),
+			Usage:    fmt.Sprintf("%s height to fork at in place of the height aligned with the L1 fork height. Must derive from an L1 block at or before the L1 fork height", chain),
+			EnvVars:  opservice.PrefixEnvVar(envPrefix, envName),
+			Category: "FORK HEIGHTS",
+		})
 	}
+	return flags
 }
 
 type ForkCLIConfig struct {
 	L1ForkHeight uint64
 	Network      string
 	Chains       []string
+
+	// Timestamp, when set, resolves the L1 fork height to the latest block at the time
+	Timestamp uint64
+
+	// L2ForkHeights overrides the fork height of chains by name
+	L2ForkHeights map[string]uint64
+
+	CacheDir string
+	Offline  bool
+
+	InteropEnabled bool
+
+	RegistryDir string
+	ChainsFile  string
+
+	// Registry of the forkable networks, the compiled registry unless loaded from RegistryDir or ChainsFile
+	Registry *Registry
+}
+
+// SuperchainRegistry returns the registry of the forkable networks
+func (c *ForkCLIConfig) SuperchainRegistry() *Registry {
+	if c.Registry == nil {
+		return DefaultRegistry
+	}
+	return c.Registry
 }
 
 type CLIConfig struct {
+	ConfigPath string
+
+	AdminPort      uint64
+	SupervisorPort uint64
+	StateDir       string
+
+	StateInterval uint64
+	AnvilRestart  bool
+
 	L1Port         uint64
 	L2StartingPort uint64
 
+	MiningMode  string
+	L1BlockTime time.Duration
+	L2BlockTime time.Duration
+
+	InteropAutoRelay      bool
+	InteropRelayerAccount uint64
+	InteropExpiryWindow   uint64
+
+	WithdrawalsAutoFinalize bool
+	WithdrawalsDelay        uint64
+	WithdrawalsAccount      uint64
+
+	L2SafetyFollowL1 bool
+	L2SafeLag        uint64
+	L2FinalizedLag   uint64
+
 	ForkConfig *ForkCLIConfig
 }
 
 func ReadCLIConfig(ctx *cli.Context) (*CLIConfig, error) {
 	cfg := &CLIConfig{
+		ConfigPath: ctx.String(ConfigFlagName),
+
+		AdminPort:      ctx.Uint64(AdminPortFlagName),
+		SupervisorPort: ctx.Uint64(SupervisorPortFlagName),
+		StateDir:       ctx.String(StateDirFlagName),
+
+		StateInterval: ctx.Uint64(StateIntervalFlagName),
+		AnvilRestart:  ctx.Bool(AnvilRestartFlagName),
+
 		L1Port:         ctx.Uint64(L1PortFlagName),
 		L2StartingPort: ctx.Uint64(L2StartingPortFlagName),
+
+		MiningMode:  ctx.String(MiningFlagName),
+		L1BlockTime: ctx.Duration(L1BlockTimeFlagName),
+		L2BlockTime: ctx.Duration(L2BlockTimeFlagName),
+
+		InteropAutoRelay:      ctx.Bool(InteropAutoRelayFlagName),
+		InteropRelayerAccount: ctx.Uint64(InteropRelayerAccountFlagName),
+		InteropExpiryWindow:   ctx.Uint64(InteropExpiryWindowFlagName),
+
+		WithdrawalsAutoFinalize: ctx.Bool(WithdrawalsAutoFinalizeFlagName),
+		WithdrawalsDelay:        ctx.Uint64(WithdrawalsDelayFlagName),
+		WithdrawalsAccount:      ctx.Uint64(WithdrawalsAccountFlagName),
+
+		L2SafetyFollowL1: ctx.Bool(L2SafetyFollowL1FlagName),
+		L2SafeLag:        ctx.Uint64(L2SafeLagFlagName),
+		L2FinalizedLag:   ctx.Uint64(L2FinalizedLagFlagName),
 	}
 
 	if ctx.Command.Name == ForkCommandName {
@@ -88,29 +358,138 @@ func ReadCLIConfig(ctx *cli.Context) (*CLIConfig, error) {
 			L1ForkHeight: ctx.Uint64(L1ForkHeightFlagName),
 			Network:      ctx.String(NetworkFlagName),
 			Chains:       ctx.StringSlice(ChainsFlagName),
+
+			CacheDir: ctx.String(ForkCacheFlagName),
+			Offline:  ctx.Bool(ForkOfflineFlagName),
+
+			InteropEnabled: ctx.Bool(ForkInteropFlagName),
+
+			Timestamp: ctx.Uint64(ForkTimestampFlagName),
+
+			RegistryDir: ctx.String(ForkRegistryFlagName),
+			ChainsFile:  ctx.String(ForkChainsFileFlagName),
+		}
+		if cfg.ForkConfig.RegistryDir != "" || cfg.ForkConfig.ChainsFile != "" {
+			registry, err := LoadRegistry(cfg.ForkConfig.RegistryDir, cfg.ForkConfig.ChainsFile, cfg.ForkConfig.Network)
+			if err != nil {
+				return nil, err
+			}
+			cfg.ForkConfig.Registry = registry
+		}
+
+		// resolved against the chains of the loaded registry by Check
+		l2ForkHeights, err := parseL2ForkHeights(ctx.StringSlice(L2ForkHeightsFlagName))
+		if err != nil {
+			return nil, err
+		}
+		for _, chain := range registryChainNames() {
+			if !ctx.IsSet(L2ForkHeightFlagName(chain)) {
+				continue
+			}
+			if _, ok := l2ForkHeights[chain]; ok {
+				return nil, fmt.Errorf("both --%s and --%s set the fork height of %s", L2ForkHeightFlagName(chain), L2ForkHeightsFlagName, chain)
+			}
+			l2ForkHeights[chain] = ctx.Uint64(L2ForkHeightFlagName(chain))
 		}
+		cfg.ForkConfig.L2ForkHeights = l2ForkHeights
 	}
 
 	return cfg, cfg.Check()
 }
 
+// parseL2ForkHeights parses the `<chain>=<height>` fork height overrides of chains outside of the compiled registry
+func parseL2ForkHeights(values []string) (map[string]uint64, error) {
+	heights := make(map[string]uint64)
+	for _, value := range values {
+		chain, heightStr, ok := strings.Cut(value, "=")
+		if !ok || chain == "" {
+			return nil, fmt.Errorf("invalid --%s `%s`, expected <chain>=<height>", L2ForkHeightsFlagName, value)
+		}
+		height, err := strconv.ParseUint(heightStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s height for %s: %w", L2ForkHeightsFlagName, chain, err)
+		}
+		if _, ok := heights[chain]; ok {
+			return nil, fmt.Errorf("--%s is set more than once for %s", L2ForkHeightsFlagName, chain)
+		}
+		heights[chain] = height
+	}
+	return heights, nil
+}
+
+func (c *CLIConfig) L1MiningConfig() (MiningConfig, error) {
+	return c.miningConfig(c.L1BlockTime)
+}
+
+func (c *CLIConfig) L2MiningConfig() (MiningConfig, error) {
+	return c.miningConfig(c.L2BlockTime)
+}
+
+func (c *CLIConfig) miningConfig(blockTime time.Duration) (MiningConfig, error) {
+	cfg := MiningConfig{BlockTime: blockTime}
+	if c.MiningMode != "" {
+		mode, err := ParseMiningMode(c.MiningMode)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Mode = mode
+	}
+	return cfg, cfg.Check()
+}
+
 // Check runs validatation on the cli configuration
 func (c *CLIConfig) Check() error {
+	if _, err := c.L1MiningConfig(); err != nil {
+		return fmt.Errorf("invalid l1 mining config: %w", err)
+	}
+	if _, err := c.L2MiningConfig(); err != nil {
+		return fmt.Errorf("invalid l2 mining config: %w", err)
+	}
+
+	if c.L2SafetyFollowL1 && (c.L2SafeLag > 0 || c.L2FinalizedLag > 0) {
+		return fmt.Errorf("--%s cannot be combined with --%s or --%s", L2SafetyFollowL1FlagName, L2SafeLagFlagName, L2FinalizedLagFlagName)
+	}
+	if c.L2FinalizedLag < c.L2SafeLag {
+		return fmt.Errorf("--%s must be at least --%s", L2FinalizedLagFlagName, L2SafeLagFlagName)
+	}
+
+	if c.StateInterval > 0 && c.StateDir == "" {
+		return fmt.Errorf("--%s requires --%s", StateIntervalFlagName, StateDirFlagName)
+	}
+
 	if c.ForkConfig != nil {
+		if c.ConfigPath != "" {
+			return fmt.Errorf("--%s is not supported in fork mode", ConfigFlagName)
+		}
+
 		forkCfg := c.ForkConfig
-		superchain, ok := registry.Superchains[forkCfg.Network]
+		registry := forkCfg.SuperchainRegistry()
+		network, ok := registry.Network(forkCfg.Network)
 		if !ok {
 			return fmt.Errorf("unrecognized superchain network `%s`, available networks: [%s]",
-				forkCfg.Network, strings.Join(superchainNetworks(), ", "))
+				forkCfg.Network, strings.Join(registry.Networks(), ", "))
 		}
 
 		// ensure every chain is apart of the network
 		for _, chain := range forkCfg.Chains {
-			if !isInSuperchain(chain, superchain) {
+			if network.ChainByName(chain) == nil {
 				return fmt.Errorf("unrecognized chain `%s` in %s superchain, available chains: [%s]",
-					chain, forkCfg.Network, strings.Join(superchainMemberChains(superchain), ", "))
+					chain, forkCfg.Network, strings.Join(network.ChainNames(), ", "))
 			}
 		}
+
+		if forkCfg.Timestamp > 0 && forkCfg.L1ForkHeight > 0 {
+			return fmt.Errorf("--%s and --%s are mutually exclusive", ForkTimestampFlagName, L1ForkHeightFlagName)
+		}
+		for chain := range forkCfg.L2ForkHeights {
+			if !slices.Contains(forkCfg.Chains, chain) {
+				return fmt.Errorf("a fork height is set for `%s`, which is not a forked chain", chain)
+			}
+		}
+
+		if forkCfg.Offline && forkCfg.CacheDir == "" {
+			return fmt.Errorf("--%s requires --%s", ForkOfflineFlagName, ForkCacheFlagName)
+		}
 	}
 
 	return nil
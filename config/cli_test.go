@@ -0,0 +1,18 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseL2ForkHeights(t *testing.T) {
+	heights, err := parseL2ForkHeights([]string{"op=120000000", "base=17000000"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"op": 120000000, "base": 17000000}, heights)
+
+	for _, values := range [][]string{{"op"}, {"=1"}, {"op=latest"}, {"op=1", "op=2"}} {
+		_, err := parseL2ForkHeights(values)
+		require.Error(t, err, values)
+	}
+}
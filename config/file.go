@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum-optimism/supersim/genesis"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/ethereum/go-ethereum/accounts"
+)
+
+const (
+	defaultL1Port         = 8545
+	defaultL2StartingPort = 9545
+)
+
+// networkConfigFile is the TOML representation of a NetworkConfig. Optional
+// fields are pointers so that unset values fall back to the defaults.
+//
+//	l2_starting_port = 9545
+//	message_expiry_window = 3600
+//
+//	[l1]
+//	name = "L1"
+//	port = 8545
+//	chain_id = 900
+//
+//	[[l2]]
+//	name = "OPChainA"
+//	chain_id = 901
+//	dependency_set = [902]
+//	mining = "interval"
+//	block_time = "500ms"
+type networkConfigFile struct {
+	L2StartingPort      *uint64           `toml:"l2_starting_port"`
+	MessageExpiryWindow uint64            `toml:"message_expiry_window"`
+	L1                  chainConfigFile   `toml:"l1"`
+	L2s                 []chainConfigFile `toml:"l2"`
+}
+
+type chainConfigFile struct {
+	Name          string             `toml:"name"`
+	ChainID       uint64             `toml:"chain_id"`
+	Port          *uint64            `toml:"port"`
+	DependencySet []uint64           `toml:"dependency_set"`
+	Mining        string             `toml:"mining"`
+	BlockTime     string             `toml:"block_time"`
+	Secrets       *secretsConfigFile `toml:"secrets"`
+}
+
+type secretsConfigFile struct {
+	Accounts       *uint64 `toml:"accounts"`
+	Mnemonic       string  `toml:"mnemonic"`
+	DerivationPath string  `toml:"derivation_path"`
+}
+
+// ReadNetworkConfigFile parses and validates the network topology declared in a TOML file. Only
+// the L1 and L2 chain ids with generated genesis deployments are supported
+func ReadNetworkConfigFile(path string) (NetworkConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file networkConfigFile
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	networkConfig, err := file.networkConfig()
+	if err != nil {
+		return NetworkConfig{}, err
+	}
+
+	return networkConfig, networkConfig.Check()
+}
+
+func (f *networkConfigFile) networkConfig() (NetworkConfig, error) {
+	networkConfig := NetworkConfig{L2StartingPort: defaultL2StartingPort}
+	if f.L2StartingPort != nil {
+		networkConfig.L2StartingPort = *f.L2StartingPort
+	}
+	networkConfig.InteropConfig.MessageExpiryWindow = f.MessageExpiryWindow
+
+	l1Deployment := genesis.GeneratedGenesisDeployment.L1
+	if f.L1.ChainID == 0 {
+		f.L1.ChainID = l1Deployment.ChainID
+	}
+	if f.L1.ChainID != l1Deployment.ChainID {
+		return networkConfig, fmt.Errorf("unsupported l1 chain id %d, only %d is available", f.L1.ChainID, l1Deployment.ChainID)
+	}
+	if f.L1.Name == "" {
+		f.L1.Name = DefaultNetworkConfig.L1Config.Name
+	}
+	if len(f.L1.DependencySet) > 0 {
+		return networkConfig, fmt.Errorf("l1 chain %s cannot have a dependency set", f.L1.Name)
+	}
+
+	l1Config, err := f.L1.chainConfig(l1Deployment.GenesisJSON, defaultL1Port)
+	if err != nil {
+		return networkConfig, err
+	}
+	networkConfig.L1Config = l1Config
+
+	for _, l2 := range f.L2s {
+		l2Deployment := l2GenesisDeployment(l2.ChainID)
+		if l2Deployment == nil {
+			return networkConfig, fmt.Errorf("unsupported l2 chain id %d, available chain ids: %v", l2.ChainID, l2GenesisChainIDs())
+		}
+
+		// L2 anvil instances bind to any available port, fronted by the op-simulator on the configured port
+		l2Config, err := l2.chainConfig(l2Deployment.GenesisJSON, 0)
+		if err != nil {
+			return networkConfig, err
+		}
+		l2Config.Port = 0
+		l2Config.L2Config = &L2Config{
+			L1ChainID:     l1Config.ChainID,
+			L1Addresses:   l2Deployment.RegistryAddressList(),
+			DependencySet: l2.DependencySet,
+		}
+		if l2.Port != nil {
+			l2Config.L2Config.Port = *l2.Port
+		}
+
+		networkConfig.L2Configs = append(networkConfig.L2Configs, l2Config)
+	}
+
+	return networkConfig, nil
+}
+
+func (f *chainConfigFile) chainConfig(genesisJSON []byte, defaultPort uint64) (ChainConfig, error) {
+	chainConfig := ChainConfig{
+		Name:          f.Name,
+		Port:          defaultPort,
+		ChainID:       f.ChainID,
+		GenesisJSON:   genesisJSON,
+		SecretsConfig: DefaultSecretsConfig,
+	}
+	if f.Port != nil {
+		chainConfig.Port = *f.Port
+	}
+
+	if f.Mining != "" {
+		mode, err := ParseMiningMode(f.Mining)
+		if err != nil {
+			return chainConfig, fmt.Errorf("invalid mining mode for chain %s: %w", f.Name, err)
+		}
+		chainConfig.MiningConfig.Mode = mode
+	}
+	if f.BlockTime != "" {
+		blockTime, err := time.ParseDuration(f.BlockTime)
+		if err != nil {
+			return chainConfig, fmt.Errorf("invalid block time for chain %s: %w", f.Name, err)
+		}
+		chainConfig.MiningConfig.BlockTime = blockTime
+	}
+
+	if f.Secrets != nil {
+		if f.Secrets.Accounts != nil {
+			chainConfig.SecretsConfig.Accounts = *f.Secrets.Accounts
+		}
+		if f.Secrets.Mnemonic != "" {
+			chainConfig.SecretsConfig.Mnemonic = f.Secrets.Mnemonic
+		}
+		if f.Secrets.DerivationPath != "" {
+			path, err := accounts.ParseDerivationPath(f.Secrets.DerivationPath)
+			if err != nil {
+				return chainConfig, fmt.Errorf("invalid derivation path for chain %s: %w", f.Name, err)
+			}
+			chainConfig.SecretsConfig.DerivationPath = path
+		}
+	}
+
+	return chainConfig, nil
+}
+
+func l2GenesisDeployment(chainID uint64) *genesis.L2GenesisDeployment {
+	for _, l2 := range genesis.GeneratedGenesisDeployment.L2s {
+		if l2.ChainID == chainID {
+			return l2
+		}
+	}
+	return nil
+}
+
+func l2GenesisChainIDs() []uint64 {
+	var chainIDs []uint64
+	for _, l2 := range genesis.GeneratedGenesisDeployment.L2s {
+		chainIDs = append(chainIDs, l2.ChainID)
+	}
+	return chainIDs
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "supersim.toml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
+
+func TestReadNetworkConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+l2_starting_port = 10000
+message_expiry_window = 3600
+
+[l1]
+port = 0
+block_time = "12s"
+
+[[l2]]
+name = "A"
+chain_id = 901
+dependency_set = [902, 903]
+mining = "auto"
+
+[[l2]]
+name = "B"
+chain_id = 902
+port = 20000
+dependency_set = [901]
+
+[l2.secrets]
+accounts = 5
+
+[[l2]]
+name = "C"
+chain_id = 903
+block_time = "500ms"
+`)
+
+	networkConfig, err := ReadNetworkConfigFile(path)
+	require.NoError(t, err)
+
+	require.Equal(t, "L1", networkConfig.L1Config.Name)
+	require.Equal(t, uint64(900), networkConfig.L1Config.ChainID)
+	require.Equal(t, uint64(0), networkConfig.L1Config.Port)
+	require.Equal(t, uint64(10000), networkConfig.L2StartingPort)
+	require.Equal(t, uint64(3600), networkConfig.InteropConfig.MessageExpiryWindow)
+
+	require.Len(t, networkConfig.L2Configs, 3)
+	require.Equal(t, []uint64{902, 903}, networkConfig.L2Configs[0].L2Config.DependencySet)
+	require.Equal(t, uint64(20000), networkConfig.L2Configs[1].L2Config.Port)
+	require.Equal(t, uint64(0), networkConfig.L2Configs[1].Port)
+	require.Equal(t, uint64(5), networkConfig.L2Configs[1].SecretsConfig.Accounts)
+	require.Equal(t, DefaultSecretsConfig, networkConfig.L2Configs[2].SecretsConfig)
+	require.Empty(t, networkConfig.L2Configs[2].L2Config.DependencySet)
+
+	require.Equal(t, MiningConfig{BlockTime: 12 * time.Second}, networkConfig.L1Config.MiningConfig)
+	require.Equal(t, MiningModeAuto, networkConfig.L2Configs[0].MiningConfig.MiningMode())
+	require.Equal(t, DefaultBlockTime, networkConfig.L2Configs[1].MiningConfig.IntervalBlockTime())
+	require.Equal(t, []string{"--block-time", "0.5"}, networkConfig.L2Configs[2].MiningConfig.AnvilArgs())
+}
+
+func TestReadNetworkConfigFileInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"no l2 chains", `[l1]`},
+		{"unsupported l1", "[l1]\nchain_id = 1\n[[l2]]\nname = \"A\"\nchain_id = 901"},
+		{"unsupported l2", "[[l2]]\nname = \"A\"\nchain_id = 10"},
+		{"duplicate chain id", "[[l2]]\nname = \"A\"\nchain_id = 901\n[[l2]]\nname = \"B\"\nchain_id = 901"},
+		{"duplicate name", "[[l2]]\nname = \"A\"\nchain_id = 901\n[[l2]]\nname = \"A\"\nchain_id = 902"},
+		{"duplicate port", "[l1]\nport = 9000\n[[l2]]\nname = \"A\"\nchain_id = 901\nport = 9000"},
+		{"port of a defaulted chain", "l2_starting_port = 9545\n[[l2]]\nname = \"A\"\nchain_id = 901\n[[l2]]\nname = \"B\"\nchain_id = 902\nport = 9545"},
+		{"self dependency", "[[l2]]\nname = \"A\"\nchain_id = 901\ndependency_set = [901]"},
+		{"unknown dependency", "[[l2]]\nname = \"A\"\nchain_id = 901\ndependency_set = [902]"},
+		{"unknown mining mode", "[[l2]]\nname = \"A\"\nchain_id = 901\nmining = \"sometimes\""},
+		{"invalid block time", "[[l2]]\nname = \"A\"\nchain_id = 901\nblock_time = \"2\""},
+		{"negative block time", "[[l2]]\nname = \"A\"\nchain_id = 901\nblock_time = \"-1s\""},
+		{"invalid toml", "[[l2]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadNetworkConfigFile(writeConfigFile(t, test.contents))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNetworkConfigCheckAccounts(t *testing.T) {
+	path := writeConfigFile(t, `
+[[l2]]
+name = "A"
+chain_id = 901
+
+[l2.secrets]
+accounts = 5
+`)
+
+	networkConfig, err := ReadNetworkConfigFile(path)
+	require.NoError(t, err)
+
+	// the relayer account must be prefunded on every l2
+	networkConfig.InteropConfig.AutoRelay = true
+	networkConfig.InteropConfig.RelayerAccountIndex = 4
+	require.NoError(t, networkConfig.Check())
+	networkConfig.InteropConfig.RelayerAccountIndex = 5
+	require.Error(t, networkConfig.Check())
+}
+
+func TestNetworkConfigSecretsAsString(t *testing.T) {
+	path := writeConfigFile(t, `
+[[l2]]
+name = "A"
+chain_id = 901
+
+[[l2]]
+name = "B"
+chain_id = 902
+
+[l2.secrets]
+accounts = 2
+mnemonic = "test test test test test test test test test test test zero"
+`)
+
+	networkConfig, err := ReadNetworkConfigFile(path)
+	require.NoError(t, err)
+	networkConfig.InteropConfig.AutoRelay = true
+	networkConfig.InteropConfig.RelayerAccountIndex = 1
+
+	// the chains sharing the default secrets are listed apart from the chain with its own
+	secrets := networkConfig.SecretsAsString()
+	require.Contains(t, secrets, "Available Accounts ("+networkConfig.L1Config.Name+", A)")
+	require.Contains(t, secrets, "Available Accounts (B)")
+
+	custom := SecretsConfig{Accounts: 2, Mnemonic: "test test test test test test test test test test test zero", DerivationPath: DefaultSecretsConfig.DerivationPath}
+	require.Contains(t, secrets, SecretsConfigAsString(&custom, []string{"B"}, map[uint64]string{1: "l2 interop relayer"}))
+}
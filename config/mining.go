@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Block time of chains mined on an interval that do not configure one
+const DefaultBlockTime = 2 * time.Second
+
+type MiningMode string
+
+const (
+	// Blocks are mined on an interval of the block time
+	MiningModeInterval MiningMode = "interval"
+
+	// A block is mined for every transaction
+	MiningModeAuto MiningMode = "auto"
+
+	// Blocks are only mined on request, i.e `anvil_mine`
+	MiningModeNone MiningMode = "none"
+)
+
+type MiningConfig struct {
+	// Defaults to MiningModeInterval when empty
+	Mode MiningMode
+
+	// Interval between blocks when mined on an interval. Defaults to DefaultBlockTime when zero
+	BlockTime time.Duration
+}
+
+func ParseMiningMode(mode string) (MiningMode, error) {
+	switch m := MiningMode(mode); m {
+	case MiningModeInterval, MiningModeAuto, MiningModeNone:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unrecognized mining mode `%s`, available modes: [%s, %s, %s]",
+			mode, MiningModeInterval, MiningModeAuto, MiningModeNone)
+	}
+}
+
+func (c *MiningConfig) Check() error {
+	if c.Mode != "" {
+		if _, err := ParseMiningMode(string(c.Mode)); err != nil {
+			return err
+		}
+	}
+	if c.BlockTime < 0 {
+		return fmt.Errorf("block time must not be negative")
+	}
+	if c.BlockTime > 0 && c.BlockTime < time.Millisecond {
+		return fmt.Errorf("block time %s is shorter than a millisecond", c.BlockTime)
+	}
+	return nil
+}
+
+func (c *MiningConfig) MiningMode() MiningMode {
+	if c.Mode == "" {
+		return MiningModeInterval
+	}
+	return c.Mode
+}
+
+// IntervalBlockTime returns the block time of a chain mined on an interval
+func (c *MiningConfig) IntervalBlockTime() time.Duration {
+	if c.BlockTime == 0 {
+		return DefaultBlockTime
+	}
+	return c.BlockTime
+}
+
+// AnvilArgs returns the anvil arguments mining blocks accordingly
+func (c *MiningConfig) AnvilArgs() []string {
+	switch c.MiningMode() {
+	case MiningModeAuto:
+		return nil // anvil automines by default
+	case MiningModeNone:
+		return []string{"--no-mining"}
+	default:
+		return []string{"--block-time", strconv.FormatFloat(c.IntervalBlockTime().Seconds(), 'f', -1, 64)}
+	}
+}
+
+func (c *MiningConfig) String() string {
+	if c.MiningMode() == MiningModeInterval {
+		return fmt.Sprintf("%s (%s)", MiningModeInterval, c.IntervalBlockTime())
+	}
+	return string(c.MiningMode())
+}
@@ -1,39 +1,269 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
 	registry "github.com/ethereum-optimism/superchain-registry/superchain"
+
+	"github.com/BurntSushi/toml"
 )
 
-func OPChainByName(superchain *registry.Superchain, name string) *registry.ChainConfig {
-	for _, id := range superchain.ChainIDs {
-		if registry.OPChains[id].Chain == name {
-			return registry.OPChains[id]
+// Registry is the set of superchain networks that can be forked, the compiled superchain registry
+// unless extended with a local registry directory or a chains file
+type Registry struct {
+	networks map[string]*SuperchainNetwork
+}
+
+// SuperchainNetwork is an L1 and the OP chains settling to it
+type SuperchainNetwork struct {
+	Name   string
+	L1     registry.SuperchainL1Info
+	Chains []*registry.ChainConfig
+}
+
+// DefaultRegistry contains the networks of the compiled superchain registry
+var DefaultRegistry = compiledRegistry()
+
+func compiledRegistry() *Registry {
+	r := &Registry{networks: make(map[string]*SuperchainNetwork)}
+	for name, superchain := range registry.Superchains {
+		network := &SuperchainNetwork{Name: name, L1: superchain.Config.L1}
+		for _, id := range superchain.ChainIDs {
+			network.Chains = append(network.Chains, registry.OPChains[id])
 		}
+		r.networks[name] = network
 	}
-	return nil
+	return r
+}
+
+// LoadRegistry extends the compiled superchain registry with the networks of a local registry
+// directory and the chains declared in a chains file, either of which may be empty
+func LoadRegistry(dir, chainsFile, network string) (*Registry, error) {
+	r := &Registry{networks: make(map[string]*SuperchainNetwork)}
+	for name, n := range DefaultRegistry.networks {
+		r.networks[name] = n
+	}
+
+	if dir != "" {
+		if err := r.loadDir(dir); err != nil {
+			return nil, fmt.Errorf("failed to load registry %s: %w", dir, err)
+		}
+	}
+	if chainsFile != "" {
+		if err := r.loadChainsFile(chainsFile, network); err != nil {
+			return nil, fmt.Errorf("failed to load chains file %s: %w", chainsFile, err)
+		}
+	}
+	return r, nil
+}
+
+// Network returns the superchain network by name
+func (r *Registry) Network(name string) (*SuperchainNetwork, bool) {
+	network, ok := r.networks[name]
+	return network, ok
 }
 
-func superchainNetworks() []string {
+// Networks returns the sorted names of the networks
+func (r *Registry) Networks() []string {
 	var networks []string
-	for name := range registry.Superchains {
+	for name := range r.networks {
 		networks = append(networks, name)
 	}
+	slices.Sort(networks)
 	return networks
 }
 
-func superchainMemberChains(superchain *registry.Superchain) []string {
+// ChainByName returns the member chain by name, nil if absent
+func (n *SuperchainNetwork) ChainByName(name string) *registry.ChainConfig {
+	for _, chain := range n.Chains {
+		if chain.Chain == name {
+			return chain
+		}
+	}
+	return nil
+}
+
+// ChainNames returns the names of the member chains
+func (n *SuperchainNetwork) ChainNames() []string {
 	var chains []string
-	for _, id := range superchain.ChainIDs {
-		chains = append(chains, registry.OPChains[id].Chain)
+	for _, chain := range n.Chains {
+		chains = append(chains, chain.Chain)
 	}
 	return chains
 }
 
-func isInSuperchain(name string, superchain *registry.Superchain) bool {
-	for _, id := range superchain.ChainIDs {
-		if registry.OPChains[id].Chain == name {
-			return true
+// loadDir reads a directory laid out like the `superchain/configs` directory of the superchain registry,
+// a directory per network holding a superchain.toml and a toml file per chain. Networks in the directory
+// replace compiled networks of the same name
+func (r *Registry) loadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	loaded := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		networkDir := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(networkDir, "superchain.toml"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		var superchainCfg registry.SuperchainConfig
+		if err := toml.Unmarshal(data, &superchainCfg); err != nil {
+			return fmt.Errorf("failed to decode %s superchain config: %w", entry.Name(), err)
+		}
+		network := &SuperchainNetwork{Name: entry.Name(), L1: superchainCfg.L1}
+
+		chainEntries, err := os.ReadDir(networkDir)
+		if err != nil {
+			return err
+		}
+		for _, chainEntry := range chainEntries {
+			if chainEntry.IsDir() || filepath.Ext(chainEntry.Name()) != ".toml" || chainEntry.Name() == "superchain.toml" {
+				continue
+			}
+
+			data, err := os.ReadFile(filepath.Join(networkDir, chainEntry.Name()))
+			if err != nil {
+				return err
+			}
+			var chainCfg registry.ChainConfig
+			if err := toml.Unmarshal(data, &chainCfg); err != nil {
+				return fmt.Errorf("failed to decode chain config %s/%s: %w", entry.Name(), chainEntry.Name(), err)
+			}
+			chainCfg.Chain = strings.TrimSuffix(chainEntry.Name(), ".toml")
+			chainCfg.Superchain = entry.Name()
+			network.Chains = append(network.Chains, &chainCfg)
+		}
+
+		r.networks[network.Name] = network
+		loaded++
+	}
+
+	if loaded == 0 {
+		return fmt.Errorf("no networks found, expected a directory per network with a superchain.toml")
+	}
+	return nil
+}
+
+// chainsFile declares chains outside of a registry, added to the forked network. The L1 section
+// is required when the network is absent from the registry, replacing the L1 of the network otherwise.
+//
+//	[l1]
+//	chain_id = 900
+//	rpc_url = "http://127.0.0.1:8545"
+//
+//	[[l2]]
+//	name = "devnet"
+//	chain_id = 901
+//	rpc_url = "http://127.0.0.1:9545"
+//	block_time = 2
+//	genesis_time = 1720000000
+//
+//	[l2.l1_addresses]
+//	OptimismPortalProxy = "0x..."
+//	L1CrossDomainMessengerProxy = "0x..."
+type chainsFile struct {
+	L1  *chainsFileL1  `toml:"l1"`
+	L2s []chainsFileL2 `toml:"l2"`
+}
+
+type chainsFileL1 struct {
+	ChainID uint64 `toml:"chain_id"`
+	RPCUrl  string `toml:"rpc_url"`
+}
+
+type chainsFileL2 struct {
+	Name          string               `toml:"name"`
+	ChainID       uint64               `toml:"chain_id"`
+	RPCUrl        string               `toml:"rpc_url"`
+	BlockTime     uint64               `toml:"block_time"`
+	GenesisTime   uint64               `toml:"genesis_time"`
+	GenesisNumber uint64               `toml:"genesis_number"`
+	L1Addresses   registry.AddressList `toml:"l1_addresses"`
+}
+
+func (r *Registry) loadChainsFile(path, networkName string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file chainsFile
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse chains file: %w", err)
+	}
+
+	// copied, leaving the compiled network intact
+	network := &SuperchainNetwork{Name: networkName}
+	if existing, ok := r.networks[networkName]; ok {
+		network.L1 = existing.L1
+		network.Chains = slices.Clone(existing.Chains)
+	} else if file.L1 == nil {
+		return fmt.Errorf("network %s is not in the registry, an l1 must be declared", networkName)
+	}
+
+	if file.L1 != nil {
+		if file.L1.ChainID == 0 || file.L1.RPCUrl == "" {
+			return fmt.Errorf("the l1 requires a chain_id and rpc_url")
+		}
+		network.L1.ChainID = file.L1.ChainID
+		network.L1.PublicRPC = file.L1.RPCUrl
+	}
+
+	for _, l2 := range file.L2s {
+		if l2.Name == "" || l2.ChainID == 0 || l2.RPCUrl == "" {
+			return fmt.Errorf("l2 chain `%s` requires a name, chain_id and rpc_url", l2.Name)
+		}
+		if l2.L1Addresses.OptimismPortalProxy == (registry.Address{}) {
+			return fmt.Errorf("l2 chain %s requires the OptimismPortalProxy l1 address", l2.Name)
+		}
+		for _, chain := range network.Chains {
+			if chain.Chain == l2.Name || chain.ChainID == l2.ChainID {
+				return fmt.Errorf("l2 chain %s conflicts with chain %s (%d) in network %s", l2.Name, chain.Chain, chain.ChainID, networkName)
+			}
+		}
+
+		chainCfg := &registry.ChainConfig{
+			Name:       l2.Name,
+			Chain:      l2.Name,
+			ChainID:    l2.ChainID,
+			PublicRPC:  l2.RPCUrl,
+			BlockTime:  l2.BlockTime,
+			Superchain: networkName,
+			Addresses:  l2.L1Addresses,
+		}
+		chainCfg.Genesis.L2Time = l2.GenesisTime
+		chainCfg.Genesis.L2.Number = l2.GenesisNumber
+		network.Chains = append(network.Chains, chainCfg)
+	}
+
+	r.networks[networkName] = network
+	return nil
+}
+
+// registryChainNames returns the unique names of the chains across every compiled superchain network
+func registryChainNames() []string {
+	var chains []string
+	for _, network := range DefaultRegistry.networks {
+		for _, chain := range network.ChainNames() {
+			if !slices.Contains(chains, chain) {
+				chains = append(chains, chain)
+			}
 		}
 	}
-	return false
+	slices.Sort(chains)
+	return chains
 }
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRegistryDir(t *testing.T) {
+	dir := t.TempDir()
+	networkDir := filepath.Join(dir, "devnet")
+	require.NoError(t, os.Mkdir(networkDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(networkDir, "superchain.toml"), []byte(`
+name = "Devnet"
+[l1]
+  chain_id = 900
+  public_rpc = "http://127.0.0.1:8545"
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(networkDir, "alpha.toml"), []byte(`
+name = "Alpha"
+chain_id = 901
+public_rpc = "http://127.0.0.1:9545"
+block_time = 2
+
+[genesis]
+  l2_time = 1720000000
+
+[addresses]
+  OptimismPortalProxy = "0x16Fc5058F25648194471939df75CF27A2fdC48BC"
+`), 0o644))
+
+	registry, err := LoadRegistry(dir, "", "devnet")
+	require.NoError(t, err)
+
+	network, ok := registry.Network("devnet")
+	require.True(t, ok)
+	require.Equal(t, uint64(900), network.L1.ChainID)
+	require.Equal(t, []string{"alpha"}, network.ChainNames())
+
+	alpha := network.ChainByName("alpha")
+	require.Equal(t, uint64(901), alpha.ChainID)
+	require.Equal(t, uint64(2), alpha.BlockTime)
+	require.Equal(t, uint64(1720000000), alpha.Genesis.L2Time)
+	require.Equal(t, "0x16Fc5058F25648194471939df75CF27A2fdC48BC", alpha.Addresses.OptimismPortalProxy.String())
+
+	// compiled networks remain available
+	_, ok = registry.Network("mainnet")
+	require.True(t, ok)
+
+	_, err = LoadRegistry(t.TempDir(), "", "devnet")
+	require.Error(t, err)
+}
+
+func TestLoadRegistryChainsFile(t *testing.T) {
+	path := writeConfigFile(t, `
+[[l2]]
+name = "private"
+chain_id = 424242
+rpc_url = "http://127.0.0.1:9545"
+block_time = 1
+genesis_time = 1720000000
+
+[l2.l1_addresses]
+OptimismPortalProxy = "0x16Fc5058F25648194471939df75CF27A2fdC48BC"
+`)
+
+	registry, err := LoadRegistry("", path, "sepolia")
+	require.NoError(t, err)
+
+	network, ok := registry.Network("sepolia")
+	require.True(t, ok)
+	private := network.ChainByName("private")
+	require.NotNil(t, private)
+	require.Equal(t, uint64(424242), private.ChainID)
+	require.Equal(t, "http://127.0.0.1:9545", private.PublicRPC)
+	require.NotNil(t, network.ChainByName("op"))
+
+	// the compiled network is left intact
+	sepolia, _ := DefaultRegistry.Network("sepolia")
+	require.Nil(t, sepolia.ChainByName("private"))
+
+	// a network outside of the registry requires an l1
+	_, err = LoadRegistry("", path, "devnet")
+	require.Error(t, err)
+
+	path = writeConfigFile(t, `
+[l1]
+chain_id = 900
+rpc_url = "http://127.0.0.1:8545"
+
+[[l2]]
+name = "private"
+chain_id = 424242
+rpc_url = "http://127.0.0.1:9545"
+
+[l2.l1_addresses]
+OptimismPortalProxy = "0x16Fc5058F25648194471939df75CF27A2fdC48BC"
+`)
+	registry, err = LoadRegistry("", path, "devnet")
+	require.NoError(t, err)
+	network, ok = registry.Network("devnet")
+	require.True(t, ok)
+	require.Equal(t, uint64(900), network.L1.ChainID)
+	require.Equal(t, "http://127.0.0.1:8545", network.L1.PublicRPC)
+	require.Equal(t, []string{"private"}, network.ChainNames())
+
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"missing rpc url", "[[l2]]\nname = \"a\"\nchain_id = 1234\n[l2.l1_addresses]\nOptimismPortalProxy = \"0x16Fc5058F25648194471939df75CF27A2fdC48BC\""},
+		{"missing portal", "[[l2]]\nname = \"a\"\nchain_id = 1234\nrpc_url = \"http://127.0.0.1:9545\""},
+		{"conflicting chain", "[[l2]]\nname = \"op\"\nchain_id = 1234\nrpc_url = \"http://127.0.0.1:9545\"\n[l2.l1_addresses]\nOptimismPortalProxy = \"0x16Fc5058F25648194471939df75CF27A2fdC48BC\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadRegistry("", writeConfigFile(t, tt.contents), "sepolia")
+			require.Error(t, err)
+		})
+	}
+}
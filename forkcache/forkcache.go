@@ -0,0 +1,351 @@
+package forkcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	ophttp "github.com/ethereum-optimism/optimism/op-service/httputil"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	host = "127.0.0.1"
+
+	jsonRpcVersion         = "2.0"
+	jsonRpcServerErrorCode = -32000
+
+	// Returned by eth_call and eth_estimateGas for a reverted execution
+	jsonRpcExecutionRevertedErrorCode = 3
+
+	upstreamTimeout = 30 * time.Second
+)
+
+// Block tags whose block changes over time. Requests referencing them, or requesting the
+// current state of the network, are always forwarded when online
+var unpinnedBlockTags = map[string]bool{"latest": true, "pending": true, "safe": true, "finalized": true}
+
+var unpinnedMethods = map[string]bool{"eth_blockNumber": true, "eth_gasPrice": true, "eth_maxPriorityFeePerGas": true, "eth_feeHistory": true}
+
+// Position of the block argument of methods defaulting to the latest block when it is omitted
+var blockParamIndex = map[string]int{
+	"eth_getBalance":          1,
+	"eth_getCode":             1,
+	"eth_getTransactionCount": 1,
+	"eth_getStorageAt":        2,
+	"eth_getProof":            2,
+	"eth_call":                1,
+	"eth_estimateGas":         1,
+	"eth_createAccessList":    1,
+}
+
+// Fields of object params referencing a block, such as the range of a log filter
+var blockFields = []string{"fromBlock", "toBlock", "blockNumber"}
+
+// Cache records the JSON-RPC responses of the forked networks to a directory, one file per network. When
+// offline, the recorded responses are replayed without any network access, making forked sessions reproducible
+type Cache struct {
+	log log.Logger
+
+	dir     string
+	offline bool
+
+	proxies []*proxy
+}
+
+func NewCache(log log.Logger, dir string, offline bool) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fork cache directory: %w", err)
+	}
+	return &Cache{log: log, dir: dir, offline: offline}, nil
+}
+
+// Proxy starts a caching JSON-RPC proxy for the network, returning the endpoint to use in place of the upstream
+func (c *Cache) Proxy(name, upstream string) (string, error) {
+	p := &proxy{
+		log:       c.log.New("fork.cache", name),
+		path:      filepath.Join(c.dir, fmt.Sprintf("%s.jsonl", name)),
+		upstream:  upstream,
+		offline:   c.offline,
+		responses: make(map[string]*entry),
+		client:    &http.Client{Timeout: upstreamTimeout},
+	}
+
+	if err := p.load(); err != nil {
+		return "", fmt.Errorf("failed to load fork cache for %s: %w", name, err)
+	}
+	if !c.offline {
+		f, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return "", fmt.Errorf("failed to open fork cache for %s: %w", name, err)
+		}
+		p.file = f
+	}
+
+	hs, err := ophttp.StartHTTPServer(net.JoinHostPort(host, "0"), p)
+	if err != nil {
+		return "", fmt.Errorf("failed to start fork cache proxy for %s: %w", name, err)
+	}
+	p.httpServer = hs
+	c.proxies = append(c.proxies, p)
+
+	p.log.Debug("started fork cache proxy", "addr", hs.Addr(), "cached.responses", len(p.responses), "offline", c.offline)
+	return fmt.Sprintf("http://%s", hs.Addr().String()), nil
+}
+
+func (c *Cache) Stop(ctx context.Context) error {
+	var errs []error
+	for _, p := range c.proxies {
+		if err := p.httpServer.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop fork cache proxy: %w", err))
+		}
+		if p.file != nil {
+			if err := p.file.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close fork cache: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type request struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// A recorded response. Only reverted executions are recorded among errors, others may be transient
+type entry struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+type proxy struct {
+	log log.Logger
+
+	path     string
+	upstream string
+	offline  bool
+	client   *http.Client
+
+	mu        sync.RWMutex
+	responses map[string]*entry
+	file      *os.File
+
+	httpServer *ophttp.HTTPServer
+}
+
+// Later entries take precedence, as responses to unpinned requests are recorded every time
+func (p *proxy) load() error {
+	f, err := os.Open(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		if p.offline {
+			return fmt.Errorf("no cached responses at %s", p.path)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var e entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("malformed entry: %w", err)
+		}
+		p.responses[cacheKey(e.Method, e.Params)] = &e
+	}
+	return scanner.Err()
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var result any
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		var reqs []*request
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse JSON-RPC batch: %s", err), http.StatusBadRequest)
+			return
+		}
+		responses := make([]*response, len(reqs))
+		for i, req := range reqs {
+			responses[i] = p.handle(r.Context(), req)
+		}
+		result = responses
+	} else {
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse JSON-RPC request: %s", err), http.StatusBadRequest)
+			return
+		}
+		result = p.handle(r.Context(), &req)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		p.log.Error("failed to write response", "err", err)
+	}
+}
+
+func (p *proxy) handle(ctx context.Context, req *request) *response {
+	key := cacheKey(req.Method, req.Params)
+
+	p.mu.RLock()
+	cached, ok := p.responses[key]
+	p.mu.RUnlock()
+
+	if ok && (p.offline || isPinned(req)) {
+		return &response{Version: jsonRpcVersion, ID: req.ID, Result: cached.Result, Error: cached.Error}
+	}
+	if p.offline {
+		p.log.Warn("fork cache miss", "method", req.Method, "params", string(req.Params))
+		return errorResponse(req.ID, fmt.Sprintf("fork cache miss for %s", req.Method))
+	}
+
+	resp, err := p.forward(ctx, req)
+	if err != nil {
+		p.log.Error("failed to forward request", "method", req.Method, "err", err)
+		return errorResponse(req.ID, err.Error())
+	}
+
+	if resp.Error == nil || isExecutionReverted(resp.Error) {
+		if err := p.record(key, &entry{Method: req.Method, Params: req.Params, Result: resp.Result, Error: resp.Error}); err != nil {
+			p.log.Error("failed to record response", "method", req.Method, "err", err)
+		}
+	}
+	return &response{Version: jsonRpcVersion, ID: req.ID, Result: resp.Result, Error: resp.Error}
+}
+
+func (p *proxy) forward(ctx context.Context, req *request) (*response, error) {
+	body, err := json.Marshal(&request{Version: jsonRpcVersion, ID: json.RawMessage("1"), Method: req.Method, Params: req.Params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.upstream, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	var resp response
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response (status %d): %w", httpResp.StatusCode, err)
+	}
+	if resp.Result == nil && resp.Error == nil {
+		resp.Result = json.RawMessage("null")
+	}
+	return &resp, nil
+}
+
+func (p *proxy) record(key string, e *entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.responses[key] = e
+	_, err = p.file.Write(append(line, '\n'))
+	return err
+}
+
+func cacheKey(method string, params json.RawMessage) string {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, params); err != nil {
+		return method + string(params)
+	}
+	return method + compacted.String()
+}
+
+// A request is pinned when the response does not change over time, i.e referencing a block by number or hash. Blocks
+// are referenced directly or through the fields of an object, a log filter without a block hash or range ending at the latest block.
+// Requests omitting the block argument, or with params that cannot be parsed, are treated as unpinned
+func isPinned(req *request) bool {
+	if unpinnedMethods[req.Method] {
+		return false
+	}
+
+	var params []json.RawMessage
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return false
+		}
+	}
+	if index, ok := blockParamIndex[req.Method]; ok && len(params) <= index {
+		return false
+	}
+	for _, param := range params {
+		var tag string
+		if err := json.Unmarshal(param, &tag); err == nil && unpinnedBlockTags[tag] {
+			return false
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(param, &fields); err != nil {
+			continue
+		}
+		for _, field := range blockFields {
+			if err := json.Unmarshal(fields[field], &tag); err == nil && unpinnedBlockTags[tag] {
+				return false
+			}
+		}
+		if req.Method == "eth_getLogs" && fields["blockHash"] == nil && (fields["fromBlock"] == nil || fields["toBlock"] == nil) {
+			return false
+		}
+	}
+	return true
+}
+
+func isExecutionReverted(errJson json.RawMessage) bool {
+	var rpcErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(errJson, &rpcErr); err != nil {
+		return false
+	}
+	return rpcErr.Code == jsonRpcExecutionRevertedErrorCode || strings.HasPrefix(rpcErr.Message, "execution reverted")
+}
+
+func errorResponse(id json.RawMessage, message string) *response {
+	errJson, _ := json.Marshal(map[string]any{"code": jsonRpcServerErrorCode, "message": message})
+	return &response{Version: jsonRpcVersion, ID: id, Error: errJson}
+}
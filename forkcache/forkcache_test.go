@@ -0,0 +1,162 @@
+package forkcache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/stretchr/testify/require"
+)
+
+// upstream answers eth_getBalance with the number of requests served so far
+func newUpstream(t *testing.T) (*httptest.Server, *atomic.Uint64) {
+	var requests atomic.Uint64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		result, _ := json.Marshal(hexutil.Uint64(requests.Add(1)))
+		require.NoError(t, json.NewEncoder(w).Encode(&response{Version: jsonRpcVersion, ID: req.ID, Result: result}))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func getBalance(t *testing.T, endpoint string, block string) (uint64, error) {
+	client, err := rpc.Dial(endpoint)
+	require.NoError(t, err)
+	defer client.Close()
+
+	var balance hexutil.Uint64
+	err = client.CallContext(context.Background(), &balance, "eth_getBalance", "0x0000000000000000000000000000000000000000", block)
+	return uint64(balance), err
+}
+
+func TestCacheRecordAndReplay(t *testing.T) {
+	upstream, requests := newUpstream(t)
+	dir := t.TempDir()
+
+	cache, err := NewCache(log.New(), dir, false)
+	require.NoError(t, err)
+	endpoint, err := cache.Proxy("mainnet", upstream.URL)
+	require.NoError(t, err)
+
+	// pinned requests are served from the cache once recorded
+	balance, err := getBalance(t, endpoint, "0x10")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), balance)
+	balance, err = getBalance(t, endpoint, "0x10")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), balance)
+	require.Equal(t, uint64(1), requests.Load())
+
+	// unpinned requests are forwarded every time, recording the latest response
+	_, err = getBalance(t, endpoint, "latest")
+	require.NoError(t, err)
+	balance, err = getBalance(t, endpoint, "latest")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), balance)
+	require.NoError(t, cache.Stop(context.Background()))
+
+	// replayed without reaching the upstream
+	upstream.Close()
+	offlineCache, err := NewCache(log.New(), dir, true)
+	require.NoError(t, err)
+	endpoint, err = offlineCache.Proxy("mainnet", upstream.URL)
+	require.NoError(t, err)
+	defer offlineCache.Stop(context.Background())
+
+	balance, err = getBalance(t, endpoint, "0x10")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), balance)
+	balance, err = getBalance(t, endpoint, "latest")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), balance)
+
+	_, err = getBalance(t, endpoint, "0x11")
+	require.ErrorContains(t, err, "fork cache miss")
+
+	// nothing was recorded for the network
+	_, err = offlineCache.Proxy("sepolia", upstream.URL)
+	require.Error(t, err)
+}
+
+func TestCacheRecordsErrors(t *testing.T) {
+	var requests atomic.Uint64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests.Add(1)
+
+		rpcErr := map[string]any{"code": jsonRpcServerErrorCode, "message": "rate limited"}
+		if req.Method == "eth_call" {
+			rpcErr = map[string]any{"code": jsonRpcExecutionRevertedErrorCode, "message": "execution reverted"}
+		}
+		errJson, _ := json.Marshal(rpcErr)
+		require.NoError(t, json.NewEncoder(w).Encode(&response{Version: jsonRpcVersion, ID: req.ID, Error: errJson}))
+	}))
+	defer upstream.Close()
+
+	cache, err := NewCache(log.New(), t.TempDir(), false)
+	require.NoError(t, err)
+	endpoint, err := cache.Proxy("mainnet", upstream.URL)
+	require.NoError(t, err)
+	defer cache.Stop(context.Background())
+
+	client, err := rpc.Dial(endpoint)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// transient errors are retried upstream
+	for range 2 {
+		_, err = getBalance(t, endpoint, "0x10")
+		require.ErrorContains(t, err, "rate limited")
+	}
+	require.Equal(t, uint64(2), requests.Load())
+
+	// reverted executions are replayed
+	call := map[string]any{"to": "0x0000000000000000000000000000000000000000"}
+	for range 2 {
+		err = client.CallContext(context.Background(), nil, "eth_call", call, "0x10")
+		require.ErrorContains(t, err, "execution reverted")
+	}
+	require.Equal(t, uint64(3), requests.Load())
+}
+
+func TestIsPinned(t *testing.T) {
+	tests := []struct {
+		method string
+		params string
+		pinned bool
+	}{
+		{"eth_getBalance", `["0x0000000000000000000000000000000000000000", "0x10"]`, true},
+		{"eth_getBalance", `["0x0000000000000000000000000000000000000000", "latest"]`, false},
+		{"eth_getBalance", `["0x0000000000000000000000000000000000000000"]`, false},
+		{"eth_getTransactionCount", `["0x0000000000000000000000000000000000000000"]`, false},
+		{"eth_getStorageAt", `["0x0000000000000000000000000000000000000000", "0x0"]`, false},
+		{"eth_getStorageAt", `["0x0000000000000000000000000000000000000000", "0x0", "0x10"]`, true},
+		{"eth_blockNumber", `[]`, false},
+		{"eth_chainId", ``, true},
+		{"eth_getBlockByNumber", `{"number": "0x10"}`, false},
+		{"eth_call", `[{"to": "0x0000000000000000000000000000000000000000"}, {"blockNumber": "0x10"}]`, true},
+		{"eth_call", `[{"to": "0x0000000000000000000000000000000000000000"}, {"blockNumber": "safe"}]`, false},
+		{"eth_call", `[{"to": "0x0000000000000000000000000000000000000000"}]`, false},
+		{"eth_getLogs", `[{"fromBlock": "0x1", "toBlock": "0x10"}]`, true},
+		{"eth_getLogs", `[{"blockHash": "0x0000000000000000000000000000000000000000000000000000000000000001"}]`, true},
+		{"eth_getLogs", `[{"fromBlock": "0x1", "toBlock": "latest"}]`, false},
+		{"eth_getLogs", `[{"fromBlock": "0x1"}]`, false},
+		{"eth_getLogs", `[{}]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+tt.params, func(t *testing.T) {
+			require.Equal(t, tt.pinned, isPinned(&request{Method: tt.method, Params: json.RawMessage(tt.params)}))
+		})
+	}
+}
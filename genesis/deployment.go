@@ -81,7 +81,7 @@ type L2GenesisDeployment struct {
 	L1DeploymentAddresses *genesis.L1Deployments
 }
 
-// Unassigned contracts -- Fault Proof, Plasma, SuperchainConfig, Roles
+// Unassigned contracts -- Plasma, SuperchainConfig, Roles, and Fault Proof contracts other than the DisputeGameFactory
 //
 // NOTE: We use the superchain registry AddressList as the canonical format for superchain
 // addresses. Any experimental contracts will be managed externally from this list. The registry
@@ -98,6 +98,7 @@ func (d *L2GenesisDeployment) RegistryAddressList() *registry.AddressList {
 		OptimismPortalProxy:               registry.Address(d.L1DeploymentAddresses.OptimismPortalProxy),
 		SystemConfigProxy:                 registry.Address(d.L1DeploymentAddresses.SystemConfigProxy),
 		ProxyAdmin:                        registry.Address(d.L1DeploymentAddresses.ProxyAdmin),
+		DisputeGameFactoryProxy:           registry.Address(d.L1DeploymentAddresses.DisputeGameFactoryProxy),
 	}
 }
 
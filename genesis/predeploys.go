@@ -0,0 +1,77 @@
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-chain-ops/genesis"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// L2ToL2CrossDomainMessengerAddr is absent from the op-service predeploys of the pinned optimism version
+var L2ToL2CrossDomainMessengerAddr = common.HexToAddress("0x4200000000000000000000000000000000000023")
+
+// Proxied predeploys introduced with interop, absent from chains that have not activated it
+var interopPredeploys = []common.Address{
+	predeploys.CrossL2InboxAddr,
+	L2ToL2CrossDomainMessengerAddr,
+}
+
+// InteropPredeployAllocs returns the accounts of the interop predeploys in the generated L2 genesis. Both the
+// proxies and the implementations behind them are included, such that the predeploys can be set on another chain
+func InteropPredeployAllocs() (types.GenesisAlloc, error) {
+	alloc, err := generatedL2Alloc()
+	if err != nil {
+		return nil, err
+	}
+
+	allocs := types.GenesisAlloc{}
+	for _, addr := range interopPredeploys {
+		proxy, ok := alloc[addr]
+		if !ok {
+			return nil, fmt.Errorf("predeploy %s is missing from the l2 genesis", addr)
+		}
+		allocs[addr] = proxy
+
+		implAddr := common.BytesToAddress(proxy.Storage[genesis.ImplementationSlot].Bytes())
+		impl, ok := alloc[implAddr]
+		if !ok {
+			return nil, fmt.Errorf("implementation %s of predeploy %s is missing from the l2 genesis", implAddr, addr)
+		}
+		allocs[implAddr] = impl
+	}
+	return allocs, nil
+}
+
+// L1BlockInteropCode returns the code of the L1Block implementation in the generated L2 genesis, which
+// extends the L1Block storage layout with the dependency set
+func L1BlockInteropCode() ([]byte, error) {
+	alloc, err := generatedL2Alloc()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, ok := alloc[predeploys.L1BlockAddr]
+	if !ok {
+		return nil, fmt.Errorf("L1Block is missing from the l2 genesis")
+	}
+	implAddr := common.BytesToAddress(proxy.Storage[genesis.ImplementationSlot].Bytes())
+	impl, ok := alloc[implAddr]
+	if !ok || len(impl.Code) == 0 {
+		return nil, fmt.Errorf("L1Block implementation %s is missing from the l2 genesis", implAddr)
+	}
+	return impl.Code, nil
+}
+
+// The predeploys are identical across the generated L2 genesis files
+func generatedL2Alloc() (types.GenesisAlloc, error) {
+	var l2Genesis core.Genesis
+	if err := json.Unmarshal(GeneratedGenesisDeployment.L2s[0].GenesisJSON, &l2Genesis); err != nil {
+		return nil, fmt.Errorf("unable to parse l2 genesis json: %w", err)
+	}
+	return l2Genesis.Alloc, nil
+}
@@ -0,0 +1,117 @@
+package opsimulator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/supersim/config"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RelayCursors returns the positions of the deposit and message relays, persisted with the chain states
+// such that a following session resumes from them. Nil until the first L1 block is relayed
+func (opSim *OpSimulator) RelayCursors() *config.RelayCursors {
+	if !opSim.l1RelayStarted.Load() {
+		return nil
+	}
+
+	l1Block := opSim.relayedL1Block.Load()
+	relayed, ok := opSim.relayedL1BlockAt(l1Block)
+	if !ok {
+		return nil
+	}
+
+	cursors := &config.RelayCursors{L1Block: l1Block, L1BlockHash: relayed.hash, L2Block: relayed.l2Block}
+
+	opSim.relayCursorsMu.Lock()
+	defer opSim.relayCursorsMu.Unlock()
+	if len(opSim.relayCursors) > 0 {
+		cursors.Messages = make(map[uint64]config.LogPosition, len(opSim.relayCursors))
+		for chainID, cursor := range opSim.relayCursors {
+			cursors.Messages[chainID] = config.LogPosition{BlockNumber: cursor.blockNumber, LogIndex: cursor.logIndex}
+		}
+	}
+	return cursors
+}
+
+// restoreRelayCursors resumes the relays from the cursors of a previous session. The L1
+// cursor is checked against the L1 once the deposit relay starts, see resumeL1Relay
+func (opSim *OpSimulator) restoreRelayCursors(cursors *config.RelayCursors) {
+	opSim.recordRelayedL1Block(cursors.L1Block, relayedL1Block{hash: cursors.L1BlockHash, l2Block: cursors.L2Block})
+	opSim.l1Head.Store(cursors.L1Block)
+	opSim.relayedL1Block.Store(cursors.L1Block)
+	opSim.l1RelayStarted.Store(true)
+
+	opSim.relayCursorsMu.Lock()
+	defer opSim.relayCursorsMu.Unlock()
+	for chainID, position := range cursors.Messages {
+		opSim.relayCursors[chainID] = logCursor{blockNumber: position.BlockNumber, logIndex: position.LogIndex}
+	}
+}
+
+// resumeL1Relay checks the cursor against the L1 prior to resuming the deposit relay. When the L1 was
+// restored from an older dump the cursor is rewound to the restored head. A cursor no longer on the L1,
+// such as one of another session, is discarded and the relay starts over from the head
+func (opSim *OpSimulator) resumeL1Relay(ctx context.Context, head *types.Header) error {
+	relayed := opSim.relayedL1Block.Load()
+	if headNumber := head.Number.Uint64(); relayed > headNumber {
+		opSim.log.Warn("l1 head is behind the relayed l1 blocks, rewinding", "l1.head", headNumber, "l1.relayed", relayed)
+		opSim.RewindDepositRelay(headNumber)
+		relayed = headNumber
+	}
+
+	block, ok := opSim.relayedL1BlockAt(relayed)
+	if ok {
+		header, err := opSim.l1Chain.EthClient().HeaderByNumber(ctx, new(big.Int).SetUint64(relayed))
+		if err != nil {
+			return fmt.Errorf("failed to fetch l1 block %d: %w", relayed, err)
+		}
+		if header.Hash() == block.hash {
+			return nil
+		}
+	}
+
+	opSim.log.Warn("relayed l1 block is not on the l1, relaying from the head", "l1.relayed", relayed)
+	opSim.forgetRelayedL1BlocksAfter(0)
+	opSim.l1RelayStarted.Store(false)
+	return nil
+}
+
+// relayCursor returns the next sent message to relay from the source chain, nil if none was relayed yet
+func (opSim *OpSimulator) relayCursor(chainID uint64) *logCursor {
+	opSim.relayCursorsMu.Lock()
+	defer opSim.relayCursorsMu.Unlock()
+
+	cursor, ok := opSim.relayCursors[chainID]
+	if !ok {
+		return nil
+	}
+	return &cursor
+}
+
+func (opSim *OpSimulator) setRelayCursor(chainID uint64, cursor logCursor) {
+	opSim.relayCursorsMu.Lock()
+	defer opSim.relayCursorsMu.Unlock()
+	opSim.relayCursors[chainID] = cursor
+}
+
+// forgetRelayCursor drops the cursor of a chain removed from the dependency set, whose
+// messages sent in the meantime are not relayed should the chain be added back
+func (opSim *OpSimulator) forgetRelayCursor(chainID uint64) {
+	opSim.relayCursorsMu.Lock()
+	defer opSim.relayCursorsMu.Unlock()
+	delete(opSim.relayCursors, chainID)
+}
+
+// advanceRelayCursor moves the cursor of the source chain past the handled log
+func (opSim *OpSimulator) advanceRelayCursor(chainID uint64, log *types.Log) {
+	opSim.relayCursorsMu.Lock()
+	defer opSim.relayCursorsMu.Unlock()
+
+	cursor := opSim.relayCursors[chainID]
+	if cursor.advance(log) {
+		opSim.relayCursors[chainID] = cursor
+	}
+}
@@ -0,0 +1,156 @@
+package opsimulator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum-optimism/supersim/config"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DependencySet returns the chains this chain currently accepts executing messages from
+func (opSim *OpSimulator) DependencySet() []uint64 {
+	opSim.dependencySetMu.RLock()
+	defer opSim.dependencySetMu.RUnlock()
+	return slices.Clone(opSim.dependencySet)
+}
+
+// AddToDependencySet submits the deposit adding the chain to the dependency set on the L2#L1BlockInterop. The
+// returned function waits for the deposit to be included, after which messages sent from the chain are relayed
+func (opSim *OpSimulator) AddToDependencySet(ctx context.Context, chainID uint64) (func(context.Context) error, error) {
+	if chainID == opSim.ChainID() {
+		return nil, fmt.Errorf("chain %d cannot depend on itself", chainID)
+	}
+	if _, ok := opSim.chains[chainID]; !ok {
+		return nil, fmt.Errorf("no chain found for chain id: %d", chainID)
+	}
+	if slices.Contains(opSim.DependencySet(), chainID) {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	tx, err := opSim.submitSetConfigDeposit(ctx, NewAddDependencyDepositTx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		if err := opSim.waitForSetConfigDeposit(ctx, tx); err != nil {
+			return err
+		}
+
+		opSim.dependencySetMu.Lock()
+		opSim.dependencySet = append(opSim.dependencySet, chainID)
+		opSim.dependencySetMu.Unlock()
+
+		opSim.log.Info("added chain to the dependency set", "chain.id", chainID)
+		return opSim.restartRelayer()
+	}, nil
+}
+
+// RemoveFromDependencySet submits the deposit removing the chain from the dependency set on the L2#L1BlockInterop. The
+// returned function waits for the deposit to be included, after which executing messages from the chain are rejected
+func (opSim *OpSimulator) RemoveFromDependencySet(ctx context.Context, chainID uint64) (func(context.Context) error, error) {
+	if !slices.Contains(opSim.DependencySet(), chainID) {
+		return nil, fmt.Errorf("chain %d is not in the dependency set", chainID)
+	}
+
+	tx, err := opSim.submitSetConfigDeposit(ctx, NewRemoveDependencyDepositTx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		if err := opSim.waitForSetConfigDeposit(ctx, tx); err != nil {
+			return err
+		}
+
+		opSim.dependencySetMu.Lock()
+		opSim.dependencySet = slices.DeleteFunc(opSim.dependencySet, func(id uint64) bool { return id == chainID })
+		opSim.dependencySetMu.Unlock()
+		opSim.forgetRelayCursor(chainID)
+
+		opSim.log.Info("removed chain from the dependency set", "chain.id", chainID)
+		return opSim.restartRelayer()
+	}, nil
+}
+
+func (opSim *OpSimulator) submitSetConfigDeposit(ctx context.Context, newDepositTx func(*big.Int, common.Hash) (*types.DepositTx, error), chainID uint64) (*types.Transaction, error) {
+	head, err := opSim.l2Chain.EthClient().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch l2 head: %w", err)
+	}
+
+	dep, err := newDepositTx(new(big.Int).SetUint64(chainID), head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create setConfig deposit tx: %w", err)
+	}
+
+	tx := types.NewTx(dep)
+	if err := opSim.l2Chain.EthSendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to send setConfig deposit tx: %w", err)
+	}
+	return tx, nil
+}
+
+// Without mining, the deposit is included with the next block mined in lockstep, leading any
+// executing message relayed thereafter. Otherwise the deposit is mined on the chain's own schedule
+func (opSim *OpSimulator) waitForSetConfigDeposit(ctx context.Context, tx *types.Transaction) error {
+	if opSim.l2Chain.Config().MiningConfig.MiningMode() == config.MiningModeNone {
+		return nil
+	}
+
+	receipt, err := bind.WaitMined(ctx, opSim.l2Chain.EthClient(), tx)
+	if err != nil {
+		return fmt.Errorf("failed waiting for setConfig deposit tx %s: %w", tx.Hash().String(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("setConfig deposit tx %s reverted", tx.Hash().String())
+	}
+	return nil
+}
+
+// The relayer subscribes to the sent messages of every chain in the dependency set. Only
+// the relayer is restarted, leaving the other background tasks undisturbed
+func (opSim *OpSimulator) restartRelayer() error {
+	if !opSim.networkConfig.InteropConfig.AutoRelay {
+		return nil
+	}
+
+	opSim.relayerMu.Lock()
+	defer opSim.relayerMu.Unlock()
+	if opSim.Stopped() {
+		return nil
+	}
+
+	opSim.stopRelayer()
+	opSim.startRelayer()
+	return nil
+}
+
+// startRelayer runs the relayer under the background tasks context. The caller
+// holds relayerMu unless the op-simulator is starting
+func (opSim *OpSimulator) startRelayer() {
+	ctx, cancel := context.WithCancel(opSim.bgTasksCtx)
+	opSim.relayerCancel = cancel
+	opSim.relayerTasks = newTaskGroup(opSim.log, "relayer failed")
+	opSim.relayerTasks.Go(func() error {
+		return opSim.relayL2ToL2Messages(ctx)
+	})
+}
+
+func (opSim *OpSimulator) stopRelayer() {
+	if opSim.relayerCancel == nil {
+		return
+	}
+
+	opSim.relayerCancel()
+	if err := opSim.relayerTasks.Wait(); err != nil {
+		opSim.log.Debug("relayer exited with an error", "err", err)
+	}
+	opSim.relayerCancel = nil
+}
@@ -1,9 +1,11 @@
 package opsimulator
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum-optimism/supersim/config"
@@ -14,72 +16,29 @@ import (
 	"github.com/ethereum/go-ethereum"
 )
 
-var _ ethereum.Subscription = &DepositTxSubscription{}
-
-type DepositTxSubscription struct {
-	logSubscription ethereum.Subscription
-	logCh           chan types.Log
-	errCh           chan error
-	doneCh          chan struct{}
-}
-
-func (d *DepositTxSubscription) Unsubscribe() {
-	d.logSubscription.Unsubscribe()
-	d.doneCh <- struct{}{}
-}
-
-func (d *DepositTxSubscription) Err() <-chan error {
-	return d.errCh
-}
-
-// transforms Deposit event logs into DepositTx
-func SubscribeDepositTx(ctx context.Context, l1Chain config.Chain, depositContractAddr common.Address, ch chan<- *types.DepositTx) (ethereum.Subscription, error) {
-	f := ethereum.FilterQuery{
+// DepositTxsInBlock returns the deposits of an L1 block in log order. Like the op-node, each deposit
+// is assigned the user deposit source hash of the L1 block hash and log index
+func DepositTxsInBlock(ctx context.Context, l1Chain config.Chain, depositContractAddr common.Address, blockHash common.Hash) ([]*types.DepositTx, error) {
+	logs, err := l1Chain.EthGetLogs(ctx, ethereum.FilterQuery{
+		BlockHash: &blockHash,
 		Addresses: []common.Address{depositContractAddr},
 		Topics:    [][]common.Hash{{derive.DepositEventABIHash}},
-	}
-
-	logCh := make(chan types.Log)
-
-	logSubscription, err := l1Chain.SubscribeFilterLogs(ctx, f, logCh)
-
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create log subscription: %w", err)
+		return nil, fmt.Errorf("failed to fetch deposit logs: %w", err)
 	}
 
-	errCh := make(chan error)
-	doneCh := make(chan struct{})
-	logErrCh := logSubscription.Err()
+	slices.SortFunc(logs, func(a, b types.Log) int { return cmp.Compare(a.Index, b.Index) })
 
-	go func() {
-		defer close(logCh)
-		defer close(errCh)
-		defer close(doneCh)
-		for {
-			select {
-			case log := <-logCh:
-				dep, err := logToDepositTx(&log)
-				if err != nil {
-					errCh <- err
-					continue
-				}
-				ch <- dep
-			case err := <-logErrCh:
-				errCh <- fmt.Errorf("log subscription error: %w", err)
-			case <-ctx.Done():
-				return
-			case <-doneCh:
-				return
-			}
+	deps := make([]*types.DepositTx, 0, len(logs))
+	for _, log := range logs {
+		dep, err := logToDepositTx(&log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode deposit log: %w", err)
 		}
-	}()
-
-	return &DepositTxSubscription{
-		logSubscription: logSubscription,
-		logCh:           logCh,
-		errCh:           errCh,
-		doneCh:          doneCh,
-	}, nil
+		deps = append(deps, dep)
+	}
+	return deps, nil
 }
 
 func logToDepositTx(log *types.Log) (*types.DepositTx, error) {
@@ -9,18 +9,14 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	optestutils "github.com/ethereum-optimism/optimism/op-service/testutils"
 
-	"github.com/ethereum-optimism/supersim/config"
 	"github.com/ethereum-optimism/supersim/testutils"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/stretchr/testify/require"
 )
 
-var _ config.Chain = &MockChainWithSubscriptions{}
-
 func createMockDepositTxs() []*types.DepositTx {
 	num := 10
 	out := make([]*types.DepositTx, num)
@@ -38,51 +34,28 @@ func createMockDepositTxs() []*types.DepositTx {
 	return out
 }
 
-type MockChainWithSubscriptions struct {
-	*testutils.MockChain
-	mockDepositTxs []*types.DepositTx
-}
-
-func (c *MockChainWithSubscriptions) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
-	go func() {
-		for _, dep := range c.mockDepositTxs {
-			log, _ := derive.MarshalDepositLogEvent(common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeef00000000"), dep)
-			ch <- *log
-
-		}
-	}()
-
-	return &testutils.MockSubscription{}, nil
-}
-
-func TestSubscribeDepositTx(t *testing.T) {
+func TestDepositTxsInBlock(t *testing.T) {
 	mockDepositTxs := createMockDepositTxs()
-	chain := MockChainWithSubscriptions{testutils.NewMockChain(), mockDepositTxs}
-
-	ctx := context.Background()
-
-	depositTxCh := make(chan *types.DepositTx, len(mockDepositTxs))
+	blockHash := common.HexToHash("0x1234")
 
-	sub, err := SubscribeDepositTx(ctx, &chain, common.HexToAddress(""), depositTxCh)
-	if err != nil {
+	// logs are returned out of order
+	var logs []types.Log
+	for i := len(mockDepositTxs) - 1; i >= 0; i-- {
+		log, err := derive.MarshalDepositLogEvent(common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeef00000000"), mockDepositTxs[i])
 		require.NoError(t, err)
+		log.BlockHash = blockHash
+		log.Index = uint(i)
+		logs = append(logs, *log)
 	}
+	chain := testutils.MockChain{Logs: logs}
 
-	for i := 0; i < len(mockDepositTxs); i++ {
-		dep := <-depositTxCh
-
-		// Source hash is lost in the marshal process
-		require.Equal(t, dep.From, mockDepositTxs[i].From)
-		require.Equal(t, dep.To, mockDepositTxs[i].To)
-		require.Equal(t, dep.Mint, mockDepositTxs[i].Mint)
-		require.Equal(t, dep.Value, mockDepositTxs[i].Value)
-		require.Equal(t, dep.Gas, mockDepositTxs[i].Gas)
-		require.Equal(t, dep.IsSystemTransaction, mockDepositTxs[i].IsSystemTransaction)
-		require.Equal(t, dep.Data, mockDepositTxs[i].Data)
+	deps, err := DepositTxsInBlock(context.Background(), &chain, common.Address{}, blockHash)
+	require.NoError(t, err)
+	require.Len(t, deps, len(mockDepositTxs))
 
+	for i, dep := range deps {
+		source := derive.UserDepositSource{L1BlockHash: blockHash, LogIndex: uint64(i)}
+		require.Equal(t, source.SourceHash(), dep.SourceHash)
+		require.Equal(t, mockDepositTxs[i].Data, dep.Data)
 	}
-
-	sub.Unsubscribe()
-
-	close(depositTxCh)
 }
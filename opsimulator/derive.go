@@ -1,6 +1,7 @@
 package opsimulator
 
 import (
+	"fmt"
 	"math/big"
 	"strings"
 
@@ -23,11 +24,20 @@ const (
 var L1BlockInteropABI, _ = abi.JSON(strings.NewReader(bindings.L1BlockInteropMetaData.ABI))
 var L1BlockAddress = common.HexToAddress(predeploys.L1Block)
 
-func NewAddDependencyDepositTx(chainID *big.Int) (*types.DepositTx, error) {
+// Dependency set changes are not derived from a log on the L1. The deposits are instead given the
+// source of an upgrade deposit, with an intent unique to the change and the L2 block it is submitted on
+func NewAddDependencyDepositTx(chainID *big.Int, l2BlockHash common.Hash) (*types.DepositTx, error) {
+	return newSetConfigDepositTx(ConfigTypeAddDependency, chainID, l2BlockHash)
+}
+
+func NewRemoveDependencyDepositTx(chainID *big.Int, l2BlockHash common.Hash) (*types.DepositTx, error) {
+	return newSetConfigDepositTx(ConfigTypeRemoveDependency, chainID, l2BlockHash)
+}
 
+func newSetConfigDepositTx(configType uint8, chainID *big.Int, l2BlockHash common.Hash) (*types.DepositTx, error) {
 	data, err := L1BlockInteropABI.Pack(
 		"setConfig",
-		ConfigTypeAddDependency,
+		configType,
 		chainID.FillBytes(make([]byte, 32)),
 	)
 
@@ -35,10 +45,9 @@ func NewAddDependencyDepositTx(chainID *big.Int) (*types.DepositTx, error) {
 		return nil, err
 	}
 
+	source := derive.UpgradeDepositSource{Intent: fmt.Sprintf("supersim: setConfig(%d, %d) after %s", configType, chainID, l2BlockHash)}
 	return &types.DepositTx{
-		// Since we're not depositing from the L1, SourceHash is empty
-		// Update when we initiate the system config tx from the L1
-		SourceHash:          common.BytesToHash(make([]byte, 0)),
+		SourceHash:          source.SourceHash(),
 		From:                derive.L1InfoDepositerAddress,
 		To:                  &L1BlockAddress,
 		Mint:                nil,
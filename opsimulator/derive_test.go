@@ -0,0 +1,32 @@
+package opsimulator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetConfigDepositTxSourceHash(t *testing.T) {
+	chainID := big.NewInt(902)
+	blockHash := common.HexToHash("0x01")
+
+	add, err := NewAddDependencyDepositTx(chainID, blockHash)
+	require.NoError(t, err)
+	require.NotEqual(t, common.Hash{}, add.SourceHash)
+
+	// removing and adding back a dependency does not repeat a deposit
+	remove, err := NewRemoveDependencyDepositTx(chainID, blockHash)
+	require.NoError(t, err)
+	readd, err := NewAddDependencyDepositTx(chainID, common.HexToHash("0x02"))
+	require.NoError(t, err)
+
+	hashes := map[common.Hash]bool{}
+	for _, dep := range []*types.DepositTx{add, remove, readd} {
+		hashes[types.NewTx(dep).Hash()] = true
+	}
+	require.Len(t, hashes, 3)
+}
@@ -5,6 +5,13 @@ import (
 	"io"
 )
 
+const (
+	jsonRpcVersion = "2.0"
+
+	jsonRpcInvalidParamsErrorCode = -32602
+	jsonRpcServerErrorCode        = -32000
+)
+
 // some parts copied over from op-geth as these are private
 
 type jsonRpcMessage struct {
@@ -17,21 +24,61 @@ type jsonRpcMessage struct {
 	// no need to include the Error/Result fields
 }
 
-func readJsonMessages(body io.Reader) ([]*jsonRpcMessage, error) {
+// isNotification returns true for messages without an id, which must not be answered
+func (msg *jsonRpcMessage) isNotification() bool {
+	return len(msg.ID) == 0
+}
+
+type jsonRpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (err *jsonRpcError) Error() string {
+	return err.Message
+}
+
+type jsonRpcErrorResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   *jsonRpcError   `json:"error"`
+
+	// responses to notifications are not sent
+	notification bool
+}
+
+func newJsonRpcErrorResponse(msg *jsonRpcMessage, err *jsonRpcError) *jsonRpcErrorResponse {
+	id := msg.ID
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &jsonRpcErrorResponse{Version: jsonRpcVersion, ID: id, Error: err, notification: msg.isNotification()}
+}
+
+// readJsonMessages decodes the request body, also indicating if the messages were sent as a batch
+func readJsonMessages(body io.Reader) ([]*jsonRpcMessage, bool, error) {
 	var rawmsg json.RawMessage
 	if err := json.NewDecoder(body).Decode(&rawmsg); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if !isJsonRpcBatch(rawmsg) {
 		msgs := []*jsonRpcMessage{{}}
 		err := json.Unmarshal(rawmsg, &msgs[0])
-		return msgs, err
+		return msgs, false, err
 	}
 
 	var msgs []*jsonRpcMessage
 	err := json.Unmarshal(rawmsg, &msgs)
-	return msgs, err
+	return msgs, true, err
+}
+
+// writeJsonMessages encodes the messages as a batch or as the single message
+func writeJsonMessages(msgs []*jsonRpcMessage, isBatch bool) ([]byte, error) {
+	if isBatch {
+		return json.Marshal(msgs)
+	}
+	return json.Marshal(msgs[0])
 }
 
 // isBatch returns true when the first non-whitespace characters is '['
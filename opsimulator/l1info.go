@@ -0,0 +1,257 @@
+package opsimulator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum-optimism/supersim/bindings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// The simulated chains activate every hardfork at genesis, selecting the Ecotone encoding
+// of the L1 attributes deposit regardless of the L2 block time
+var l1InfoRollupConfig = &rollup.Config{RegolithTime: new(uint64), EcotoneTime: new(uint64)}
+
+// Delay before resubscribing to L1 heads after the subscription fails
+const l1ResubscribeDelay = time.Second
+
+// DepositRelayStatus reports the progress of relaying L1 blocks to the L2
+type DepositRelayStatus struct {
+	// Latest L1 block observed
+	L1Head uint64 `json:"l1Head"`
+
+	// Last L1 block whose L1 attributes and deposits were submitted
+	RelayedL1Block uint64 `json:"relayedL1Block"`
+
+	// Number of L1 blocks observed but not yet relayed
+	Lag uint64 `json:"lag"`
+}
+
+func (opSim *OpSimulator) DepositRelayStatus() DepositRelayStatus {
+	status := DepositRelayStatus{L1Head: opSim.l1Head.Load(), RelayedL1Block: opSim.relayedL1Block.Load()}
+	if status.L1Head > status.RelayedL1Block {
+		status.Lag = status.L1Head - status.RelayedL1Block
+	}
+	return status
+}
+
+// RewindDepositRelay moves the cursor back to the L1 block, if ahead of it. Used when the
+// L1 is reverted, so that the blocks mined thereafter are relayed
+func (opSim *OpSimulator) RewindDepositRelay(l1Block uint64) {
+	for {
+		relayed := opSim.relayedL1Block.Load()
+		if relayed <= l1Block || opSim.relayedL1Block.CompareAndSwap(relayed, l1Block) {
+			break
+		}
+	}
+	opSim.forgetRelayedL1BlocksAfter(l1Block)
+	opSim.l1Head.Store(l1Block)
+}
+
+// Tracks the L1 head and submits, for every L1 block, the L1 attributes deposit followed by the
+// deposits of the block in log order. This matches the deposits an op-node includes at the start
+// of the first L2 block of an epoch, with sequence number zero. The following blocks of the epoch
+// are led by an L1 attributes deposit of their own when sealed by the op-simulator, see sealBlock.
+//
+// The last relayed L1 block is kept as a cursor, persisting across restarts of the task and, with a state
+// directory, across sessions. When the head subscription fails it is resubscribed, and blocks missed in the
+// meantime are backfilled from the cursor
+func (opSim *OpSimulator) relayL1Blocks(ctx context.Context) error {
+	sysCfg, err := opSim.l1BlockSystemConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read system config from the L1Block predeploy: %w", err)
+	}
+
+	head, err := opSim.l1Chain.EthClient().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l1 head: %w", err)
+	}
+	if opSim.l1RelayStarted.Load() {
+		if err := opSim.resumeL1Relay(ctx, head); err != nil {
+			return err
+		}
+	}
+
+	// bring the L1Block predeploy up to date with the current head, whose deposits precede the simulator
+	if !opSim.l1RelayStarted.Load() {
+		l2Head, err := opSim.l2Chain.EthClient().BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch l2 head: %w", err)
+		}
+		deposits, err := opSim.submitDepositTxs(ctx, head, nil, sysCfg)
+		if err != nil {
+			opSim.log.Error("failed to submit l1 info deposit", "l1.number", head.Number, "err", err)
+		}
+
+		opSim.recordRelayedL1Block(head.Number.Uint64(), relayedL1Block{hash: head.Hash(), l2Block: l2Head, deposits: deposits})
+
+		opSim.l1Head.Store(head.Number.Uint64())
+		opSim.relayedL1Block.Store(head.Number.Uint64())
+		opSim.l1RelayStarted.Store(true)
+	}
+
+	for {
+		err := opSim.followL1Heads(ctx, sysCfg)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		opSim.log.Warn("l1 head subscription failed, resubscribing", "err", err, "l1.relayed", opSim.relayedL1Block.Load())
+		select {
+		case <-time.After(l1ResubscribeDelay):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Relays L1 blocks until the head subscription fails
+func (opSim *OpSimulator) followL1Heads(ctx context.Context, sysCfg eth.SystemConfig) error {
+	headCh := make(chan *types.Header)
+	sub, err := opSim.l1Chain.EthClient().SubscribeNewHead(ctx, headCh)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to l1 heads: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	// backfill blocks mined while unsubscribed. Subsequent heads are relayed from the cursor, so
+	// heads delivered between subscribing and fetching the head are not relayed twice
+	head, err := opSim.l1Chain.EthClient().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l1 head: %w", err)
+	}
+	opSim.relayL1BlocksTo(ctx, head, sysCfg)
+
+	for {
+		select {
+		case head := <-headCh:
+			opSim.relayL1BlocksTo(ctx, head, sysCfg)
+
+		case err := <-sub.Err():
+			return fmt.Errorf("l1 head subscription failed: %w", err)
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Relays every L1 block after the cursor up to and including the head, after handling a reorg of the relayed
+// blocks. Stops at the first block that fails to be relayed, which is retried from the cursor on the next head
+func (opSim *OpSimulator) relayL1BlocksTo(ctx context.Context, head *types.Header, sysCfg eth.SystemConfig) {
+	headNumber := head.Number.Uint64()
+	opSim.l1Head.Store(headNumber)
+
+	if err := opSim.handleL1Reorg(ctx, head); err != nil {
+		opSim.log.Error("failed to handle l1 reorg", "l1.number", headNumber, "err", err)
+		return
+	}
+
+	from := opSim.relayedL1Block.Load() + 1
+	if headNumber > from {
+		opSim.log.Debug("backfilling l1 blocks", "from", from, "to", headNumber)
+	}
+
+	for number := from; number <= headNumber; number++ {
+		if err := opSim.relayL1Block(ctx, number, sysCfg); err != nil {
+			opSim.log.Error("failed to relay l1 block", "l1.number", number, "err", err)
+			return
+		}
+		if !opSim.relayedL1Block.CompareAndSwap(number-1, number) {
+			return // rewound while relaying the block
+		}
+	}
+}
+
+func (opSim *OpSimulator) relayL1Block(ctx context.Context, number uint64, sysCfg eth.SystemConfig) error {
+	head, err := opSim.l1Chain.EthClient().HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return fmt.Errorf("failed to fetch l1 block: %w", err)
+	}
+
+	deps, err := DepositTxsInBlock(ctx, opSim.l1Chain, common.Address(opSim.L2Config.L1Addresses.OptimismPortalProxy), head.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to fetch deposits: %w", err)
+	}
+
+	l2Head, err := opSim.l2Chain.EthClient().BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l2 head: %w", err)
+	}
+	deposits, err := opSim.submitDepositTxs(ctx, head, deps, sysCfg)
+	if err != nil {
+		return err
+	}
+
+	opSim.recordRelayedL1Block(number, relayedL1Block{hash: head.Hash(), l2Block: l2Head, deposits: deposits})
+	return nil
+}
+
+// Submits the L1 attributes deposit of the L1 block followed by the block's deposits, in order,
+// returning the hashes of the submitted deposits
+func (opSim *OpSimulator) submitDepositTxs(ctx context.Context, head *types.Header, deps []*types.DepositTx, sysCfg eth.SystemConfig) ([]common.Hash, error) {
+	l1Info, err := derive.L1InfoDeposit(l1InfoRollupConfig, sysCfg, 0, eth.HeaderBlockInfo(head), head.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create l1 info deposit: %w", err)
+	}
+
+	opSim.depositsMu.RLock()
+	defer opSim.depositsMu.RUnlock()
+
+	// pending transactions are sealed first, leaving the deposits to lead the next block, the first of the epoch
+	if opSim.sealsBlocks() {
+		opSim.sealMu.Lock()
+		defer opSim.sealMu.Unlock()
+		if err := opSim.sealPendingTxs(ctx); err != nil {
+			return nil, err
+		}
+		opSim.epoch = epoch{origin: head, sysCfg: sysCfg, pending: true}
+	}
+
+	var hashes []common.Hash
+	for _, dep := range append([]*types.DepositTx{l1Info}, deps...) {
+		depTx := types.NewTx(dep)
+		if err := opSim.l2Chain.EthSendTransaction(ctx, depTx); err != nil {
+			return hashes, fmt.Errorf("failed to send deposit tx %s: %w", depTx.Hash().String(), err)
+		}
+		opSim.log.Debug("submitted deposit tx", "l1.number", head.Number, "hash", depTx.Hash().String())
+		hashes = append(hashes, depTx.Hash())
+	}
+	return hashes, nil
+}
+
+// The batcher and fee scalars are not changed by the simulator, so the values
+// found in the L2 genesis (or fork) are carried into every L1 attributes deposit
+func (opSim *OpSimulator) l1BlockSystemConfig(ctx context.Context) (eth.SystemConfig, error) {
+	l1Block, err := bindings.NewL1BlockInteropCaller(L1BlockAddress, opSim.l2Chain.EthClient())
+	if err != nil {
+		return eth.SystemConfig{}, fmt.Errorf("failed to bind to L1Block: %w", err)
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	batcherHash, err := l1Block.BatcherHash(callOpts)
+	if err != nil {
+		return eth.SystemConfig{}, fmt.Errorf("failed to read batcher hash: %w", err)
+	}
+	baseFeeScalar, err := l1Block.BaseFeeScalar(callOpts)
+	if err != nil {
+		return eth.SystemConfig{}, fmt.Errorf("failed to read base fee scalar: %w", err)
+	}
+	blobBaseFeeScalar, err := l1Block.BlobBaseFeeScalar(callOpts)
+	if err != nil {
+		return eth.SystemConfig{}, fmt.Errorf("failed to read blob base fee scalar: %w", err)
+	}
+
+	return eth.SystemConfig{
+		BatcherAddr: common.BytesToAddress(batcherHash[:]),
+		Scalar:      eth.EncodeScalar(eth.EcotoneScalars{BaseFeeScalar: baseFeeScalar, BlobBaseFeeScalar: blobBaseFeeScalar}),
+	}, nil
+}
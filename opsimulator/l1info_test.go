@@ -0,0 +1,288 @@
+package opsimulator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	registry "github.com/ethereum-optimism/superchain-registry/superchain"
+
+	"github.com/ethereum-optimism/supersim/config"
+	"github.com/ethereum-optimism/supersim/testutils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/stretchr/testify/require"
+)
+
+// l1HeadsAPI serves a chain of headers up to the head, and head subscriptions which fail while failHeads is set
+type l1HeadsAPI struct {
+	mu        sync.Mutex
+	headers   []*types.Header
+	head      uint64
+	failHeads bool
+	notifier  *rpc.Notifier
+	sub       *rpc.Subscription
+}
+
+func newL1HeadsAPI(blocks uint64) *l1HeadsAPI {
+	api := &l1HeadsAPI{}
+	parentHash := common.Hash{}
+	for number := uint64(0); number < blocks; number++ {
+		header := &types.Header{
+			ParentHash: parentHash,
+			Number:     new(big.Int).SetUint64(number),
+			Time:       1000 + 12*number,
+			Difficulty: new(big.Int),
+			BaseFee:    big.NewInt(1),
+		}
+		api.headers = append(api.headers, header)
+		parentHash = header.Hash()
+	}
+	return api
+}
+
+func (api *l1HeadsAPI) GetBlockByNumber(number rpc.BlockNumber, _ bool) (*types.Header, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	n := uint64(number)
+	if number == rpc.LatestBlockNumber {
+		n = api.head
+	}
+	if n > api.head {
+		return nil, nil
+	}
+	return api.headers[n], nil
+}
+
+func (api *l1HeadsAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.failHeads {
+		return nil, errors.New("head subscription unavailable")
+	}
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	api.notifier = notifier
+	api.sub = notifier.CreateSubscription()
+	return api.sub, nil
+}
+
+// setHead moves the head, notifying the subscriber if any
+func (api *l1HeadsAPI) setHead(head uint64) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.head = head
+	if api.sub != nil {
+		_ = api.notifier.Notify(api.sub.ID, api.headers[head])
+	}
+}
+
+func (api *l1HeadsAPI) setFailHeads(fail bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.failHeads = fail
+}
+
+func (api *l1HeadsAPI) subscribed() bool {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.sub != nil
+}
+
+type l2HeadAPI struct{}
+
+func (api *l2HeadAPI) BlockNumber() hexutil.Uint64 {
+	return 0
+}
+
+func dialInProc(t *testing.T, api any) *ethclient.Client {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", api))
+	t.Cleanup(server.Stop)
+
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(client.Close)
+	return client
+}
+
+// MockChainWithSentTxs records the transactions sent to the chain
+type MockChainWithSentTxs struct {
+	*testutils.MockChain
+
+	mu      sync.Mutex
+	sentTxs []common.Hash
+}
+
+func (c *MockChainWithSentTxs) EthSendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sentTxs = append(c.sentTxs, tx.Hash())
+	return nil
+}
+
+func (c *MockChainWithSentTxs) SentTxs() []common.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]common.Hash{}, c.sentTxs...)
+}
+
+func TestFollowL1HeadsResubscribe(t *testing.T) {
+	l1API := newL1HeadsAPI(10)
+	l1Chain := &testutils.MockChain{Client: dialInProc(t, l1API)}
+	l2Chain := &MockChainWithSentTxs{MockChain: &testutils.MockChain{
+		Client:      dialInProc(t, &l2HeadAPI{}),
+		ChainConfig: &config.ChainConfig{MiningConfig: config.MiningConfig{Mode: config.MiningModeAuto}},
+	}}
+
+	opSim := &OpSimulator{
+		log:             testlog.Logger(t, log.LevelInfo),
+		l1Chain:         l1Chain,
+		l2Chain:         l2Chain,
+		L2Config:        &config.L2Config{L1Addresses: &registry.AddressList{}},
+		relayedL1Blocks: make(map[uint64]relayedL1Block),
+	}
+	sysCfg := eth.SystemConfig{}
+
+	// relayed up to block 1
+	l1API.setHead(1)
+	opSim.recordRelayedL1Block(1, relayedL1Block{hash: l1API.headers[1].Hash()})
+	opSim.relayedL1Block.Store(1)
+
+	// blocks are mined while the subscription fails
+	l1API.setFailHeads(true)
+	l1API.setHead(4)
+	require.Error(t, opSim.followL1Heads(context.Background(), sysCfg))
+	require.Empty(t, l2Chain.SentTxs())
+
+	// the missed blocks are backfilled once resubscribed
+	l1API.setFailHeads(false)
+	l1API.setHead(6)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- opSim.followL1Heads(ctx, sysCfg) }()
+
+	require.Eventually(t, func() bool {
+		return l1API.subscribed() && opSim.relayedL1Block.Load() == 6
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// heads already backfilled are not relayed again
+	l1API.setHead(6)
+	l1API.setHead(7)
+	require.Eventually(t, func() bool {
+		return opSim.relayedL1Block.Load() == 7
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	var expected []common.Hash
+	for number := 2; number <= 7; number++ {
+		header := l1API.headers[number]
+		l1Info, err := derive.L1InfoDeposit(l1InfoRollupConfig, sysCfg, 0, eth.HeaderBlockInfo(header), header.Time)
+		require.NoError(t, err)
+		expected = append(expected, types.NewTx(l1Info).Hash())
+	}
+	require.Equal(t, expected, l2Chain.SentTxs())
+}
+
+func TestResumeL1Relay(t *testing.T) {
+	l1API := newL1HeadsAPI(10)
+	l1API.setHead(3)
+	l1Chain := &testutils.MockChain{Client: dialInProc(t, l1API)}
+
+	newOpSim := func(cursors *config.RelayCursors) *OpSimulator {
+		opSim := &OpSimulator{
+			log:             testlog.Logger(t, log.LevelInfo),
+			l1Chain:         l1Chain,
+			relayedL1Blocks: make(map[uint64]relayedL1Block),
+			relayCursors:    make(map[uint64]logCursor),
+		}
+		opSim.restoreRelayCursors(cursors)
+		return opSim
+	}
+	head := l1API.headers[3]
+
+	// the L1 restored from an older dump rewinds the cursor to its head
+	opSim := newOpSim(&config.RelayCursors{L1Block: 6, L1BlockHash: l1API.headers[6].Hash(), L2Block: 12})
+	opSim.recordRelayedL1Block(3, relayedL1Block{hash: head.Hash(), l2Block: 6})
+	require.NoError(t, opSim.resumeL1Relay(context.Background(), head))
+	require.True(t, opSim.l1RelayStarted.Load())
+	require.Equal(t, uint64(3), opSim.relayedL1Block.Load())
+	require.Equal(t, uint64(3), opSim.l1Head.Load())
+
+	// a cursor on the L1 is resumed from
+	opSim = newOpSim(&config.RelayCursors{L1Block: 2, L1BlockHash: l1API.headers[2].Hash(), L2Block: 4})
+	require.NoError(t, opSim.resumeL1Relay(context.Background(), head))
+	require.True(t, opSim.l1RelayStarted.Load())
+	require.Equal(t, uint64(2), opSim.relayedL1Block.Load())
+
+	// a cursor no longer on the L1 is discarded
+	opSim = newOpSim(&config.RelayCursors{L1Block: 2, L1BlockHash: common.HexToHash("0x1234"), L2Block: 4})
+	require.NoError(t, opSim.resumeL1Relay(context.Background(), head))
+	require.False(t, opSim.l1RelayStarted.Load())
+}
+
+type evmMineAPI struct {
+	mu    sync.Mutex
+	mined int
+}
+
+func (api *evmMineAPI) Mine() {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.mined++
+}
+
+func TestSealBlockEpochSequence(t *testing.T) {
+	server := rpc.NewServer()
+	mineAPI := &evmMineAPI{}
+	require.NoError(t, server.RegisterName("evm", mineAPI))
+	t.Cleanup(server.Stop)
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(client.Close)
+
+	l2Chain := &MockChainWithSentTxs{MockChain: &testutils.MockChain{Client: client}}
+	opSim := &OpSimulator{log: testlog.Logger(t, log.LevelInfo), l2Chain: l2Chain}
+
+	// without an epoch, blocks are sealed without a deposit
+	require.NoError(t, opSim.sealBlock(context.Background()))
+	require.Empty(t, l2Chain.SentTxs())
+
+	// the first block of the epoch is led by the deposit submitted with the deposits of the origin
+	origin := newL1HeadsAPI(1).headers[0]
+	sysCfg := eth.SystemConfig{}
+	opSim.epoch = epoch{origin: origin, sysCfg: sysCfg, pending: true}
+	require.NoError(t, opSim.sealBlock(context.Background()))
+	require.Empty(t, l2Chain.SentTxs())
+
+	// the following blocks of the epoch increment the sequence number
+	require.NoError(t, opSim.sealBlock(context.Background()))
+	require.NoError(t, opSim.sealBlock(context.Background()))
+
+	var expected []common.Hash
+	for seq := uint64(1); seq <= 2; seq++ {
+		l1Info, err := derive.L1InfoDeposit(l1InfoRollupConfig, sysCfg, seq, eth.HeaderBlockInfo(origin), origin.Time)
+		require.NoError(t, err)
+		expected = append(expected, types.NewTx(l1Info).Hash())
+	}
+	require.Equal(t, expected, l2Chain.SentTxs())
+	require.Equal(t, 4, mineAPI.mined)
+}
@@ -0,0 +1,67 @@
+package opsimulator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TODO: import the ABI from the monorepo snapshots once a loader is available
+const l2ToL2CrossDomainMessengerABI = `[{"type":"function","name":"sendMessage","inputs":[{"name":"_destination","type":"uint256"},{"name":"_target","type":"address"},{"name":"_message","type":"bytes"}],"outputs":[],"stateMutability":"payable"},{"type":"function","name":"relayMessage","inputs":[{"name":"_destination","type":"uint256"},{"name":"_source","type":"uint256"},{"name":"_nonce","type":"uint256"},{"name":"_sender","type":"address"},{"name":"_target","type":"address"},{"name":"_message","type":"bytes"}],"outputs":[],"stateMutability":"payable"}]`
+
+const (
+	methodRelayMessage = "relayMessage"
+)
+
+var L2ToL2CrossDomainMessengerAddress = common.HexToAddress("0x4200000000000000000000000000000000000023")
+var L2ToL2CrossDomainMessengerABI, _ = abi.JSON(strings.NewReader(l2ToL2CrossDomainMessengerABI))
+
+var (
+	ErrNotSentMessage = errors.New("log is not a sent message")
+)
+
+// A message initiated with `L2ToL2CrossDomainMessenger#sendMessage`. The messenger emits the
+// abi encoded `relayMessage` calldata as an anonymous log which serves as the initiating message
+type sentMessage struct {
+	Destination *big.Int
+	Source      *big.Int
+	Nonce       *big.Int
+	Sender      common.Address
+	Target      common.Address
+	Message     []byte
+
+	// The raw log data, used as the payload of the executing message
+	Payload []byte
+}
+
+func decodeSentMessageLog(l *types.Log) (*sentMessage, error) {
+	if l.Address != L2ToL2CrossDomainMessengerAddress || len(l.Topics) != 0 {
+		return nil, ErrNotSentMessage
+	}
+
+	method := L2ToL2CrossDomainMessengerABI.Methods[methodRelayMessage]
+	if len(l.Data) < 4 || !bytes.Equal(l.Data[:4], method.ID) {
+		return nil, ErrNotSentMessage
+	}
+
+	args, err := method.Inputs.Unpack(l.Data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack relayMessage calldata: %w", err)
+	}
+
+	return &sentMessage{
+		Destination: args[0].(*big.Int),
+		Source:      args[1].(*big.Int),
+		Nonce:       args[2].(*big.Int),
+		Sender:      args[3].(common.Address),
+		Target:      args[4].(common.Address),
+		Message:     args[5].([]byte),
+		Payload:     l.Data,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package opsimulator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSentMessageLog(t *testing.T) {
+	sender := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	target := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeef00000000")
+	data, err := L2ToL2CrossDomainMessengerABI.Pack(methodRelayMessage, big.NewInt(902), big.NewInt(901), big.NewInt(7), sender, target, []byte{0x01, 0x02})
+	require.NoError(t, err)
+
+	msg, err := decodeSentMessageLog(&types.Log{Address: L2ToL2CrossDomainMessengerAddress, Data: data})
+	require.NoError(t, err)
+	require.Equal(t, uint64(902), msg.Destination.Uint64())
+	require.Equal(t, uint64(901), msg.Source.Uint64())
+	require.Equal(t, uint64(7), msg.Nonce.Uint64())
+	require.Equal(t, sender, msg.Sender)
+	require.Equal(t, target, msg.Target)
+	require.Equal(t, []byte{0x01, 0x02}, msg.Message)
+	require.Equal(t, data, msg.Payload)
+
+	// logs with topics, such as RelayedMessage, are not sent messages
+	_, err = decodeSentMessageLog(&types.Log{Address: L2ToL2CrossDomainMessengerAddress, Topics: []common.Hash{{}}, Data: data})
+	require.ErrorIs(t, err, ErrNotSentMessage)
+
+	// logs from other contracts are ignored
+	_, err = decodeSentMessageLog(&types.Log{Address: target, Data: data})
+	require.ErrorIs(t, err, ErrNotSentMessage)
+}
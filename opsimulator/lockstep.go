@@ -0,0 +1,67 @@
+package opsimulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const depositPollInterval = 50 * time.Millisecond
+
+// WaitForDeposits waits until the deposits derived from the L1 block, including the L1 attributes
+// deposit, have been submitted to this chain. Used to relay the deposits prior to mining the L2 block
+func (opSim *OpSimulator) WaitForDeposits(ctx context.Context, l1Head *types.Header) error {
+	hashes, err := opSim.depositTxHashes(ctx, l1Head)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(depositPollInterval)
+	defer ticker.Stop()
+
+	for len(hashes) > 0 {
+		_, _, err := opSim.l2Chain.EthClient().TransactionByHash(ctx, hashes[0])
+		if err == nil {
+			hashes = hashes[1:]
+			continue
+		} else if !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("failed to query deposit tx %s: %w", hashes[0], err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("deposit tx %s was not submitted: %w", hashes[0], ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (opSim *OpSimulator) depositTxHashes(ctx context.Context, l1Head *types.Header) ([]common.Hash, error) {
+	sysCfg, err := opSim.l1BlockSystemConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system config from the L1Block predeploy: %w", err)
+	}
+	l1Info, err := derive.L1InfoDeposit(l1InfoRollupConfig, sysCfg, 0, eth.HeaderBlockInfo(l1Head), l1Head.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create l1 info deposit: %w", err)
+	}
+	hashes := []common.Hash{types.NewTx(l1Info).Hash()}
+
+	deps, err := DepositTxsInBlock(ctx, opSim.l1Chain, common.Address(opSim.L2Config.L1Addresses.OptimismPortalProxy), l1Head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	for _, dep := range deps {
+		hashes = append(hashes, types.NewTx(dep).Hash())
+	}
+	return hashes, nil
+}
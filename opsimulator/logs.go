@@ -0,0 +1,126 @@
+package opsimulator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/supersim/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const logsResubscribeDelay = time.Second
+
+// Streams the logs matching the query from the cursor or, when nil, emitted after the current head of the chain. When
+// the subscription fails it is resubscribed, and the logs emitted in the meantime are backfilled from the last seen log
+func (opSim *OpSimulator) followLogs(ctx context.Context, chain config.Chain, query ethereum.FilterQuery, from *logCursor, logCh chan<- types.Log) {
+	if from != nil {
+		next := *from
+		opSim.subscribeLogsFrom(ctx, chain, query, &next, logCh)
+		return
+	}
+
+	head, err := chain.EthClient().BlockNumber(ctx)
+	for err != nil {
+		opSim.log.Warn("failed to fetch chain head, retrying", "chain.id", chain.ChainID(), "err", err)
+		select {
+		case <-time.After(logsResubscribeDelay):
+		case <-ctx.Done():
+			return
+		}
+		head, err = chain.EthClient().BlockNumber(ctx)
+	}
+
+	// logs of blocks after the head are yet to be seen
+	next := logCursor{blockNumber: head + 1}
+	opSim.subscribeLogsFrom(ctx, chain, query, &next, logCh)
+}
+
+// Resubscribes to the logs following the cursor until the context is cancelled
+func (opSim *OpSimulator) subscribeLogsFrom(ctx context.Context, chain config.Chain, query ethereum.FilterQuery, next *logCursor, logCh chan<- types.Log) {
+	for {
+		err := opSim.subscribeLogs(ctx, chain, query, next, logCh)
+		if ctx.Err() != nil {
+			return
+		}
+
+		opSim.log.Warn("log subscription failed, resubscribing", "chain.id", chain.ChainID(), "err", err, "block", next.blockNumber)
+		select {
+		case <-time.After(logsResubscribeDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Position of the next log to be seen
+type logCursor struct {
+	blockNumber uint64
+	logIndex    uint
+}
+
+// advance moves the cursor past the log, reporting false if the log was already seen
+func (c *logCursor) advance(log *types.Log) bool {
+	if log.BlockNumber < c.blockNumber || (log.BlockNumber == c.blockNumber && log.Index < c.logIndex) {
+		return false
+	}
+	c.blockNumber, c.logIndex = log.BlockNumber, log.Index+1
+	return true
+}
+
+// Forwards logs following the cursor until the subscription fails
+func (opSim *OpSimulator) subscribeLogs(ctx context.Context, chain config.Chain, query ethereum.FilterQuery, next *logCursor, logCh chan<- types.Log) error {
+	subCh := make(chan types.Log)
+	sub, err := chain.SubscribeFilterLogs(ctx, query, subCh)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to logs: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	// backfill logs emitted while unsubscribed. Logs also delivered by the subscription are
+	// forwarded only once, when advancing the cursor
+	head, err := chain.EthClient().BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch chain head: %w", err)
+	}
+	var logs []types.Log
+	if head >= next.blockNumber {
+		query.FromBlock, query.ToBlock = new(big.Int).SetUint64(next.blockNumber), new(big.Int).SetUint64(head)
+		logs, err = chain.EthGetLogs(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to backfill logs: %w", err)
+		}
+	}
+	for i := range logs {
+		if next.advance(&logs[i]) {
+			select {
+			case logCh <- logs[i]:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+
+	for {
+		select {
+		case log := <-subCh:
+			if log.Removed || !next.advance(&log) {
+				continue
+			}
+			select {
+			case logCh <- log:
+			case <-ctx.Done():
+				return nil
+			}
+
+		case err := <-sub.Err():
+			return fmt.Errorf("log subscription failed: %w", err)
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
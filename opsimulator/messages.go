@@ -0,0 +1,89 @@
+package opsimulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/supersim/config"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrInitiatingMessageNotFound = errors.New("initiating message not found")
+
+// CheckExecutingMessage validates a message executed on this chain in a block with the given timestamp
+func (opSim *OpSimulator) CheckExecutingMessage(ctx context.Context, id MessageIdentifier, payloadHash common.Hash, executingTimestamp uint64) error {
+	isDependency, err := opSim.isInDependencySet(ctx, id.ChainId.Uint64())
+	if err != nil {
+		return err
+	}
+	if !isDependency {
+		return fmt.Errorf("chain id %d is not in the dependency set", id.ChainId)
+	}
+
+	sourceChain, ok := opSim.chains[id.ChainId.Uint64()]
+	if !ok {
+		return fmt.Errorf("no chain found for chain id: %d", id.ChainId)
+	}
+
+	if err := CheckInitiatingMessage(ctx, sourceChain, id, payloadHash); err != nil {
+		return err
+	}
+	return CheckMessageTiming(id.Timestamp.Uint64(), executingTimestamp, opSim.MessageExpiryWindow())
+}
+
+// CheckInitiatingMessage validates that the identifier references a log on the source chain with the payload hash
+func CheckInitiatingMessage(ctx context.Context, sourceChain config.Chain, id MessageIdentifier, payloadHash common.Hash) error {
+	identifierBlock, err := sourceChain.EthBlockByNumber(ctx, id.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("failed to fetch executing message block: %w", err)
+	}
+
+	if id.Timestamp.Cmp(new(big.Int).SetUint64(identifierBlock.Time())) != 0 {
+		return errors.New("executing message identifier does not match block timestamp")
+	}
+
+	log, err := InitiatingMessageLog(ctx, sourceChain, id.BlockNumber, id.LogIndex.Uint64())
+	if err != nil {
+		return err
+	}
+	if log.Address != id.Origin {
+		return ErrInitiatingMessageNotFound
+	}
+
+	if crypto.Keccak256Hash(MessagePayloadBytes(log)) != payloadHash {
+		return errors.New("executing and initiating message fields are not equal")
+	}
+	return nil
+}
+
+// InitiatingMessageLog returns the log at the index within the block of the source chain
+func InitiatingMessageLog(ctx context.Context, sourceChain config.Chain, blockNumber *big.Int, logIndex uint64) (*types.Log, error) {
+	logs, err := sourceChain.EthGetLogs(ctx, ethereum.FilterQuery{FromBlock: blockNumber, ToBlock: blockNumber})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch initiating message logs: %w", err)
+	}
+
+	for _, log := range logs {
+		if uint64(log.Index) == logIndex {
+			return &log, nil
+		}
+	}
+	return nil, ErrInitiatingMessageNotFound
+}
+
+// CheckMessageTiming validates that the initiating message was sent before the
+// executing block and that it has not expired
+func CheckMessageTiming(initiatingTimestamp, executingTimestamp, expiryWindow uint64) error {
+	if initiatingTimestamp >= executingTimestamp {
+		return fmt.Errorf("initiating message timestamp %d is not before the executing block timestamp %d", initiatingTimestamp, executingTimestamp)
+	}
+	if executingTimestamp > initiatingTimestamp+expiryWindow {
+		return fmt.Errorf("initiating message at timestamp %d expired after %d seconds", initiatingTimestamp, expiryWindow)
+	}
+	return nil
+}
@@ -12,27 +12,38 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	ophttp "github.com/ethereum-optimism/optimism/op-service/httputil"
 	"github.com/ethereum-optimism/optimism/op-service/tasks"
 
 	"github.com/ethereum-optimism/supersim/anvil"
+	"github.com/ethereum-optimism/supersim/bindings"
 	"github.com/ethereum-optimism/supersim/config"
-	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/gorilla/websocket"
 )
 
 const (
 	host = "127.0.0.1"
+
+	batchForwardTimeout = 30 * time.Second
 )
 
+// Forwards the valid subset of batches containing rejected messages
+var batchHttpClient = &http.Client{Timeout: batchForwardTimeout}
+
 type OpSimulator struct {
 	log log.Logger
 
@@ -41,12 +52,50 @@ type OpSimulator struct {
 
 	L2Config *config.L2Config
 
+	// Initialized from the L2Config and modified at runtime through the admin server
+	dependencySetMu sync.RWMutex
+	dependencySet   []uint64
+
+	networkConfig *config.NetworkConfig
+
 	// Long running tasks
 	bgTasks       tasks.Group
 	bgTasksCtx    context.Context
 	bgTasksCancel context.CancelFunc
 	chains        map[uint64]config.Chain
 
+	// The L2ToL2 relayer runs apart from the other background tasks, such that
+	// it alone is restarted when the dependency set changes
+	relayerMu     sync.Mutex
+	relayerTasks  tasks.Group
+	relayerCancel context.CancelFunc
+
+	// Held by background tasks while submitting deposits. Locked
+	// exclusively to pause deposit relaying
+	depositsMu sync.RWMutex
+
+	// Held exclusively while sealing a block or submitting deposits to an L2 whose
+	// blocks are sealed by the op-simulator, and shared while forwarding transactions
+	sealMu sync.RWMutex
+
+	// L1 origin of the blocks sealed by the op-simulator. Guarded by sealMu
+	epoch epoch
+
+	// Progress of relaying L1 blocks, kept across restarts of the background tasks
+	l1RelayStarted atomic.Bool
+	l1Head         atomic.Uint64
+	relayedL1Block atomic.Uint64
+
+	relayedL1BlocksMu sync.Mutex
+	relayedL1Blocks   map[uint64]relayedL1Block
+
+	// Next sent message to relay by source chain, kept across restarts of the relayer
+	relayCursorsMu sync.Mutex
+	relayCursors   map[uint64]logCursor
+
+	// Advances the clock of every chain, mining an L1 block observing the warp
+	warp func(ctx context.Context, seconds uint64) error
+
 	// One time tasks at startup
 	startupTasks       tasks.Group
 	startupTasksCtx    context.Context
@@ -58,7 +107,7 @@ type OpSimulator struct {
 	stopped atomic.Bool
 }
 
-func New(log log.Logger, port uint64, l1Chain, l2Chain config.Chain, l2Config *config.L2Config, anvilChains map[uint64]*anvil.Anvil) *OpSimulator {
+func New(log log.Logger, port uint64, l1Chain, l2Chain config.Chain, l2Config *config.L2Config, networkConfig *config.NetworkConfig, anvilChains map[uint64]*anvil.Anvil) *OpSimulator {
 	bgTasksCtx, bgTasksCancel := context.WithCancel(context.Background())
 	startupTasksCtx, startupTasksCancel := context.WithCancel(context.Background())
 
@@ -67,29 +116,45 @@ func New(log log.Logger, port uint64, l1Chain, l2Chain config.Chain, l2Config *c
 		chains[chainId] = chain
 	}
 
-	return &OpSimulator{
+	opSim := &OpSimulator{
 		port:     port,
 		log:      log,
 		l1Chain:  l1Chain,
 		l2Chain:  l2Chain,
 		L2Config: l2Config,
 
+		dependencySet: slices.Clone(l2Config.DependencySet),
+
+		relayedL1Blocks: make(map[uint64]relayedL1Block),
+		relayCursors:    make(map[uint64]logCursor),
+
+		networkConfig: networkConfig,
+
 		bgTasksCtx:    bgTasksCtx,
 		bgTasksCancel: bgTasksCancel,
-		bgTasks: tasks.Group{
-			HandleCrit: func(err error) {
-				log.Error("bg task failed", "err", err)
-			},
-		},
+		bgTasks:       newTaskGroup(log, "bg task failed"),
 
 		startupTasksCtx:    startupTasksCtx,
 		startupTasksCancel: startupTasksCancel,
-		startupTasks: tasks.Group{
-			HandleCrit: func(err error) {
-				log.Error("startup task failed", err)
-			},
+		startupTasks:       newTaskGroup(log, "startup task failed"),
+		chains:             chains,
+	}
+	if l2Config.RelayCursors != nil {
+		opSim.restoreRelayCursors(l2Config.RelayCursors)
+	}
+	return opSim
+}
+
+// SetWarp sets how the clocks are advanced to finalize withdrawals instantly. Must be called before Start
+func (opSim *OpSimulator) SetWarp(warp func(ctx context.Context, seconds uint64) error) {
+	opSim.warp = warp
+}
+
+func newTaskGroup(log log.Logger, failureMsg string) tasks.Group {
+	return tasks.Group{
+		HandleCrit: func(err error) {
+			log.Error(failureMsg, "err", err)
 		},
-		chains: chains,
 	}
 }
 
@@ -142,40 +207,84 @@ func (opSim *OpSimulator) Stopped() bool {
 	return opSim.stopped.Load()
 }
 
+// PauseDeposits blocks the submission of deposits to the L2 until ResumeDeposits is called.
+// Deposits observed while paused are submitted once resumed
+func (opSim *OpSimulator) PauseDeposits() {
+	opSim.depositsMu.Lock()
+}
+
+func (opSim *OpSimulator) ResumeDeposits() {
+	opSim.depositsMu.Unlock()
+}
+
+// RestartTasks reruns the startup tasks and restarts the background tasks. Used after one
+// of the underlying chains has been restarted, invalidating subscriptions held by the tasks
+func (opSim *OpSimulator) RestartTasks() error {
+	opSim.relayerMu.Lock()
+	defer opSim.relayerMu.Unlock()
+
+	opSim.bgTasksCancel()
+	if err := opSim.bgTasks.Wait(); err != nil {
+		opSim.log.Debug("bg tasks exited with an error", "err", err)
+	}
+	opSim.stopRelayer()
+
+	// fresh groups, such that errors of the previous tasks are not reported by the restarted ones
+	opSim.bgTasks = newTaskGroup(opSim.log, "bg task failed")
+	opSim.startupTasks = newTaskGroup(opSim.log, "startup task failed")
+	opSim.bgTasksCtx, opSim.bgTasksCancel = context.WithCancel(context.Background())
+
+	opSim.startStartupTasks()
+	if err := opSim.startupTasks.Wait(); err != nil {
+		return fmt.Errorf("failed to rerun startup tasks: %w", err)
+	}
+
+	opSim.startBackgroundTasks()
+	return nil
+}
+
 func (opSim *OpSimulator) startStartupTasks() {
-	for _, chainID := range opSim.L2Config.DependencySet {
+	depSet := opSim.DependencySet()
+	for _, chainID := range depSet {
 		opSim.startupTasks.Go(func() error {
-			return opSim.AddDependency(chainID, opSim.L2Config.DependencySet)
+			// a chain started from a previously dumped state may already have the dependency
+			isDependency, err := opSim.isInDependencySet(opSim.startupTasksCtx, chainID)
+			if err != nil {
+				return err
+			}
+			if isDependency {
+				return nil
+			}
+
+			return opSim.AddDependency(chainID, depSet)
 		})
 	}
 }
 
 func (opSim *OpSimulator) startBackgroundTasks() {
-	// Relay deposit tx from L1 to L2
+	// Relay the L1 attributes and deposit txs of every L1 block to L2
 	opSim.bgTasks.Go(func() error {
-		depositTxCh := make(chan *types.DepositTx)
-		sub, err := SubscribeDepositTx(context.Background(), opSim.l1Chain, common.Address(opSim.L2Config.L1Addresses.OptimismPortalProxy), depositTxCh)
-		if err != nil {
-			return fmt.Errorf("failed to subscribe to deposit tx: %w", err)
-		}
+		return opSim.relayL1Blocks(opSim.bgTasksCtx)
+	})
 
-		for {
-			select {
-			case dep := <-depositTxCh:
-				depTx := types.NewTx(dep)
-				opSim.log.Debug("received deposit tx", "hash", depTx.Hash().String())
-				if err := opSim.l2Chain.EthSendTransaction(opSim.bgTasksCtx, depTx); err != nil {
-					opSim.log.Error("failed to submit deposit tx: %w", err)
-				}
+	// Seal the blocks of an L2 mined on an interval
+	if opSim.sealsBlocks() {
+		opSim.bgTasks.Go(func() error {
+			return opSim.sealBlocks(opSim.bgTasksCtx)
+		})
+	}
 
-				opSim.log.Debug("submitted deposit tx", "hash", depTx.Hash().String())
+	// Relay L2ToL2CrossDomainMessenger messages to this chain
+	if opSim.networkConfig.InteropConfig.AutoRelay {
+		opSim.startRelayer()
+	}
 
-			case <-opSim.bgTasksCtx.Done():
-				sub.Unsubscribe()
-				close(depositTxCh)
-			}
-		}
-	})
+	// Prove and finalize withdrawals from this chain on the L1
+	if opSim.networkConfig.WithdrawalConfig.AutoFinalize {
+		opSim.bgTasks.Go(func() error {
+			return opSim.finalizeWithdrawals(opSim.bgTasksCtx)
+		})
+	}
 }
 
 func (opSim *OpSimulator) handler(proxy *httputil.ReverseProxy, ctx context.Context) http.HandlerFunc {
@@ -189,51 +298,188 @@ func (opSim *OpSimulator) handler(proxy *httputil.ReverseProxy, ctx context.Cont
 			return
 		}
 
+		if websocket.IsWebSocketUpgrade(r) {
+			opSim.handleWebSocket(ctx, w, r)
+			return
+		}
+
 		// setup an intermediate buffer so the request body is inspectable
 		var buf bytes.Buffer
 		body := io.TeeReader(r.Body, &buf)
 		r.Body = io.NopCloser(&buf)
 
 		// decode the fields we're interested in inspecting
-		msgs, err := readJsonMessages(body)
+		msgs, isBatch, err := readJsonMessages(body)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Failed to parse JSON-RPC request: %s", err), http.StatusBadRequest)
 			return
 		}
 
-		for _, msg := range msgs {
-			if msg.Method == "eth_sendRawTransaction" {
-				var params []hexutil.Bytes
-				if err := json.Unmarshal(msg.Params, &params); err != nil {
-					http.Error(w, fmt.Sprintf("bad params sent to eth_sendRawTransaction: %s", err), http.StatusBadRequest)
-					return
-				}
-				if len(params) != 1 {
-					http.Error(w, "eth_sendRawTransaction request has invalid number of params", http.StatusBadRequest)
-					return
-				}
-				tx := new(types.Transaction)
-				if err := tx.UnmarshalBinary(params[0]); err != nil {
-					http.Error(w, fmt.Sprintf("failed to decode transaction data: %s", err), http.StatusBadRequest)
-					return
-				}
-
-				if err := opSim.checkInteropInvariants(ctx, tx); err != nil {
-					opSim.log.Error(fmt.Sprintf("interop invariants not met: %s", err))
-					// TODO (https://github.com/ethereum-optimism/supersim/issues/79) for batch requests write error to individual tx
-					http.Error(w, fmt.Sprintf("interop invariants not met: %s", err), http.StatusBadRequest)
-					return
-				}
+		if opSim.resolveSafetyTags(ctx, msgs) {
+			data, err := writeJsonMessages(msgs, isBatch)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to encode JSON-RPC request: %s", err), http.StatusInternalServerError)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(data))
+			r.ContentLength = int64(len(data))
+		}
+
+		validMsgs, errResponses := opSim.checkJsonRpcMessages(ctx, msgs)
+		defer opSim.holdSealing(validMsgs)()
+		if len(errResponses) == 0 {
+			proxy.ServeHTTP(w, r)
+			return
+		}
+
+		if !isBatch {
+			if errResponses[0].notification {
+				w.WriteHeader(http.StatusOK)
+				return
 			}
+			writeJsonResponse(w, errResponses[0])
+			return
+		}
+
+		responses, err := opSim.partialBatchResponses(r.Context(), r.Header, validMsgs, errResponses)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if len(responses) == 0 {
+			// a batch of notifications is not answered
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		writeJsonResponse(w, responses)
+	}
+}
+
+// checkJsonRpcMessages splits the messages into those that can be forwarded to the
+// underlying chain and error responses for those that cannot
+func (opSim *OpSimulator) checkJsonRpcMessages(ctx context.Context, msgs []*jsonRpcMessage) ([]*jsonRpcMessage, []*jsonRpcErrorResponse) {
+	var validMsgs []*jsonRpcMessage
+	var errResponses []*jsonRpcErrorResponse
+	for _, msg := range msgs {
+		if err := opSim.checkJsonRpcMessage(ctx, msg); err != nil {
+			errResponses = append(errResponses, newJsonRpcErrorResponse(msg, err))
+		} else {
+			validMsgs = append(validMsgs, msg)
 		}
+	}
+	return validMsgs, errResponses
+}
+
+// partialBatchResponses forwards only the valid subset of a batch, with the headers of the client request when
+// sent over http. Batch responses may be returned in any order so the error responses are appended to the
+// forwarded results, leaving out those of notifications
+func (opSim *OpSimulator) partialBatchResponses(ctx context.Context, header http.Header, validMsgs []*jsonRpcMessage, errResponses []*jsonRpcErrorResponse) ([]json.RawMessage, error) {
+	responses := make([]json.RawMessage, 0, len(validMsgs)+len(errResponses))
+	if len(validMsgs) > 0 {
+		results, err := opSim.forwardJsonRpcBatch(ctx, header, validMsgs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to forward JSON-RPC batch: %w", err)
+		}
+		responses = append(responses, results...)
+	}
+	for _, errResponse := range errResponses {
+		if errResponse.notification {
+			continue
+		}
+		response, err := json.Marshal(errResponse)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode JSON-RPC error: %w", err)
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// checkJsonRpcMessage inspects an individual JSON-RPC message, returning an error
+// if the message should not be forwarded to the underlying chain
+func (opSim *OpSimulator) checkJsonRpcMessage(ctx context.Context, msg *jsonRpcMessage) *jsonRpcError {
+	if msg.Method != "eth_sendRawTransaction" {
+		return nil
+	}
+
+	var params []hexutil.Bytes
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return &jsonRpcError{Code: jsonRpcInvalidParamsErrorCode, Message: fmt.Sprintf("bad params sent to eth_sendRawTransaction: %s", err)}
+	}
+	if len(params) != 1 {
+		return &jsonRpcError{Code: jsonRpcInvalidParamsErrorCode, Message: "eth_sendRawTransaction request has invalid number of params"}
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(params[0]); err != nil {
+		return &jsonRpcError{Code: jsonRpcInvalidParamsErrorCode, Message: fmt.Sprintf("failed to decode transaction data: %s", err)}
+	}
 
-		proxy.ServeHTTP(w, r)
+	if err := opSim.checkInteropInvariants(ctx, tx); err != nil {
+		opSim.log.Error(fmt.Sprintf("interop invariants not met: %s", err))
+		return &jsonRpcError{Code: jsonRpcServerErrorCode, Message: fmt.Sprintf("interop invariants not met: %s", err)}
 	}
+
+	return nil
+}
+
+// forwardJsonRpcBatch sends the batch directly to the underlying chain, returning the individual responses
+func (opSim *OpSimulator) forwardJsonRpcBatch(ctx context.Context, header http.Header, msgs []*jsonRpcMessage) ([]json.RawMessage, error) {
+	body, err := json.Marshal(msgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opSim.l2Chain.Endpoint(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if header != nil {
+		req.Header = header.Clone()
+
+		// set by the transport for the forwarded body, which is decoded here
+		for _, h := range []string{"Content-Length", "Accept-Encoding", "Connection"} {
+			req.Header.Del(h)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := batchHttpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// a batch of notifications is not answered
+	var results []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode batch response: %w", err)
+	}
+
+	return results, nil
+}
+
+func writeJsonResponse(w http.ResponseWriter, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode JSON-RPC response: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
 }
 
 // Update dependency set on the L2#L1BlockInterop using a deposit tx
 func (opSim *OpSimulator) AddDependency(chainID uint64, depSet []uint64) error {
-	dep, err := NewAddDependencyDepositTx(big.NewInt(int64(chainID)))
+	head, err := opSim.l2Chain.EthClient().HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l2 head: %w", err)
+	}
+
+	dep, err := NewAddDependencyDepositTx(big.NewInt(int64(chainID)), head.Hash())
 
 	if err != nil {
 		return fmt.Errorf("failed to create setConfig deposit tx: %w", err)
@@ -246,6 +492,27 @@ func (opSim *OpSimulator) AddDependency(chainID uint64, depSet []uint64) error {
 	return nil
 }
 
+// MessageExpiryWindow returns the seconds after which an initiating message can no longer be executed
+func (opSim *OpSimulator) MessageExpiryWindow() uint64 {
+	if opSim.networkConfig.InteropConfig.MessageExpiryWindow == 0 {
+		return config.DefaultMessageExpiryWindow
+	}
+	return opSim.networkConfig.InteropConfig.MessageExpiryWindow
+}
+
+func (opSim *OpSimulator) isInDependencySet(ctx context.Context, chainID uint64) (bool, error) {
+	l1BlockInterop, err := bindings.NewL1BlockInteropCaller(L1BlockAddress, opSim.l2Chain.EthClient())
+	if err != nil {
+		return false, fmt.Errorf("failed to bind to L1Block: %w", err)
+	}
+
+	isDependency, err := l1BlockInterop.IsInDependencySet(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(chainID))
+	if err != nil {
+		return false, fmt.Errorf("failed to query the dependency set: %w", err)
+	}
+	return isDependency, nil
+}
+
 func (opSim *OpSimulator) checkInteropInvariants(ctx context.Context, tx *types.Transaction) error {
 	from, err := getFromAddress(tx)
 
@@ -276,52 +543,16 @@ func (opSim *OpSimulator) checkInteropInvariants(ctx context.Context, tx *types.
 	}
 
 	if len(executingMessages) >= 1 {
-		for _, executingMessage := range executingMessages {
-			sourceChain, ok := opSim.chains[executingMessage.Identifier.ChainId.Uint64()]
-			if !ok {
-				return fmt.Errorf("no chain found for chain id: %d", executingMessage.Identifier.ChainId)
-			}
-
-			identifierBlock, err := sourceChain.EthBlockByNumber(ctx, executingMessage.Identifier.BlockNumber)
-			if err != nil {
-				return fmt.Errorf("failed to fetch executing message block: %w", err)
-			}
-
-			if executingMessage.Identifier.Timestamp.Cmp(new(big.Int).SetUint64(identifierBlock.Time())) != 0 {
-				return fmt.Errorf("executing message identifier does not match block timestamp: %w", err)
-			}
-
-			logs, err := sourceChain.EthGetLogs(
-				ctx,
-				ethereum.FilterQuery{
-					Addresses: []common.Address{executingMessage.Identifier.Origin},
-					FromBlock: executingMessage.Identifier.BlockNumber,
-					ToBlock:   executingMessage.Identifier.BlockNumber,
-				},
-			)
-			if err != nil {
-				return fmt.Errorf("failed to fetch initiating message logs: %w", err)
-			}
-			var initiatingMessageLogs []types.Log
-			for _, log := range logs {
-				logIndex := big.NewInt(int64(log.Index))
-				if logIndex.Cmp(executingMessage.Identifier.LogIndex) == 0 {
-					initiatingMessageLogs = append(initiatingMessageLogs, log)
-				}
-			}
-
-			if len(initiatingMessageLogs) == 0 {
-				return fmt.Errorf("initiating message not found")
-			}
-
-			// Since we look for a log at a specific index, this should never be more than 1.
-			if len(initiatingMessageLogs) > 1 {
-				return fmt.Errorf("unexpected number of initiating messages found: %d", len(initiatingMessageLogs))
-			}
+		// the transaction is included in the pending block
+		pendingHeader, err := opSim.l2Chain.EthClient().HeaderByNumber(ctx, big.NewInt(rpc.PendingBlockNumber.Int64()))
+		if err != nil {
+			return fmt.Errorf("failed to fetch pending block: %w", err)
+		}
+		executingTimestamp := pendingHeader.Time
 
-			initiatingMsgPayloadHash := crypto.Keccak256Hash(messagePayloadBytes(&initiatingMessageLogs[0]))
-			if common.BytesToHash(executingMessage.MsgHash[:]).Cmp(initiatingMsgPayloadHash) != 0 {
-				return fmt.Errorf("executing and initiating message fields are not equal")
+		for _, executingMessage := range executingMessages {
+			if err := opSim.CheckExecutingMessage(ctx, executingMessage.Identifier, executingMessage.MsgHash, executingTimestamp); err != nil {
+				return err
 			}
 		}
 	}
@@ -354,7 +585,8 @@ func toSimulatedLogs(call config.TraceCallRaw) []types.Log {
 	return logs
 }
 
-func messagePayloadBytes(log *types.Log) []byte {
+// MessagePayloadBytes returns the payload of a message, the concatenated topics and data of the log
+func MessagePayloadBytes(log *types.Log) []byte {
 	msg := []byte{}
 	for _, topic := range log.Topics {
 		msg = append(msg, topic.Bytes()...)
@@ -385,6 +617,14 @@ func (opSim *OpSimulator) Endpoint() string {
 	return fmt.Sprintf("http://%s:%d", host, opSim.port)
 }
 
+func (opSim *OpSimulator) Port() uint64 {
+	return opSim.port
+}
+
+func (opSim *OpSimulator) WSEndpoint() string {
+	return fmt.Sprintf("ws://%s:%d", host, opSim.port)
+}
+
 func (opSim *OpSimulator) Name() string {
 	return opSim.l2Chain.Name()
 }
@@ -393,12 +633,16 @@ func (opSim *OpSimulator) ChainID() uint64 {
 	return opSim.l2Chain.ChainID()
 }
 
+func (opSim *OpSimulator) LogPath() string {
+	return opSim.l2Chain.LogPath()
+}
+
 func (opSim *OpSimulator) Config() *config.ChainConfig {
 	return opSim.l2Chain.Config()
 }
 
 func (opSim *OpSimulator) String() string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "Name: %s    Chain ID: %d    RPC: %s    LogPath: %s", opSim.Name(), opSim.ChainID(), opSim.Endpoint(), opSim.l2Chain.LogPath())
+	fmt.Fprintf(&b, "Name: %s    Chain ID: %d    RPC: %s    Mining: %s    LogPath: %s", opSim.Name(), opSim.ChainID(), opSim.Endpoint(), opSim.Config().MiningConfig.String(), opSim.LogPath())
 	return b.String()
 }
@@ -0,0 +1,156 @@
+package opsimulator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum-optimism/supersim/hdaccount"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Relays messages sent via the L2ToL2CrossDomainMessenger from chains in the dependency
+// set by submitting the executing message to this chain's CrossL2Inbox
+func (opSim *OpSimulator) relayL2ToL2Messages(ctx context.Context) error {
+	secrets := opSim.l2Chain.Config().SecretsConfig
+	hdAccountStore, err := hdaccount.NewHdAccountStore(secrets.Mnemonic, secrets.DerivationPath)
+	if err != nil {
+		return fmt.Errorf("failed to create hd account store: %w", err)
+	}
+	privateKey, err := hdAccountStore.DerivePrivateKeyAt(opSim.networkConfig.InteropConfig.RelayerAccountIndex)
+	if err != nil {
+		return fmt.Errorf("failed to derive relayer private key: %w", err)
+	}
+
+	// prefunded by anvil, though a chain's own secrets or restored state may leave it without funds
+	relayerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	balance, err := opSim.l2Chain.EthClient().BalanceAt(ctx, relayerAddr, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch relayer balance: %w", err)
+	}
+	if balance.Sign() == 0 {
+		return fmt.Errorf("relayer account %s is not funded on chain %s", relayerAddr, opSim.l2Chain.Name())
+	}
+
+	logCh := make(chan sourceLog)
+	for _, chainID := range opSim.DependencySet() {
+		sourceChain, ok := opSim.chains[chainID]
+		if !ok {
+			return fmt.Errorf("no chain found for chain id: %d", chainID)
+		}
+
+		// resumed from the cursor, such that messages sent while the relayer was stopped are relayed
+		from := opSim.relayCursor(chainID)
+		if from == nil {
+			head, err := sourceChain.EthClient().BlockNumber(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to fetch head of chain %d: %w", chainID, err)
+			}
+			from = &logCursor{blockNumber: head + 1}
+			opSim.setRelayCursor(chainID, *from)
+		}
+
+		sourceLogCh := make(chan types.Log)
+		query := ethereum.FilterQuery{Addresses: []common.Address{L2ToL2CrossDomainMessengerAddress}}
+		go opSim.followLogs(ctx, sourceChain, query, from, sourceLogCh)
+		go func() {
+			for {
+				select {
+				case log := <-sourceLogCh:
+					select {
+					case logCh <- sourceLog{chainID: chainID, log: log}:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case sourceLog := <-logCh:
+			opSim.handleSentMessageLog(ctx, privateKey, &sourceLog.log)
+			opSim.advanceRelayCursor(sourceLog.chainID, &sourceLog.log)
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// A log of the chain it was emitted on
+type sourceLog struct {
+	chainID uint64
+	log     types.Log
+}
+
+// Relays the sent message of the log if destined to this chain
+func (opSim *OpSimulator) handleSentMessageLog(ctx context.Context, privateKey *ecdsa.PrivateKey, log *types.Log) {
+	if log.Removed {
+		return
+	}
+
+	msg, err := decodeSentMessageLog(log)
+	if errors.Is(err, ErrNotSentMessage) {
+		return
+	} else if err != nil {
+		opSim.log.Error("failed to decode sent message", "err", err)
+		return
+	}
+
+	if msg.Destination.Uint64() != opSim.ChainID() {
+		return
+	}
+
+	opSim.log.Debug("received sent message", "source", msg.Source, "nonce", msg.Nonce, "tx.hash", log.TxHash.String())
+	tx, err := opSim.relayMessage(ctx, privateKey, log, msg)
+	if err != nil {
+		opSim.log.Error("failed to relay message", "source", msg.Source, "nonce", msg.Nonce, "err", err)
+		return
+	}
+
+	opSim.log.Debug("relayed message", "source", msg.Source, "nonce", msg.Nonce, "hash", tx.Hash().String())
+}
+
+func (opSim *OpSimulator) relayMessage(ctx context.Context, privateKey *ecdsa.PrivateKey, log *types.Log, msg *sentMessage) (*types.Transaction, error) {
+	sourceChain, ok := opSim.chains[msg.Source.Uint64()]
+	if !ok {
+		return nil, fmt.Errorf("no chain found for chain id: %d", msg.Source)
+	}
+
+	blockNumber := new(big.Int).SetUint64(log.BlockNumber)
+	block, err := sourceChain.EthBlockByNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch initiating message block: %w", err)
+	}
+
+	identifier := MessageIdentifier{
+		Origin:      log.Address,
+		BlockNumber: blockNumber,
+		LogIndex:    new(big.Int).SetUint64(uint64(log.Index)),
+		Timestamp:   new(big.Int).SetUint64(block.Time()),
+		ChainId:     msg.Source,
+	}
+
+	transactor, err := bind.NewKeyedTransactorWithChainID(privateKey, new(big.Int).SetUint64(opSim.ChainID()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relayer transactor: %w", err)
+	}
+	transactor.Context = ctx
+
+	client := opSim.l2Chain.EthClient()
+	crossL2Inbox := NewCrossL2Inbox()
+	contract := bind.NewBoundContract(predeploys.CrossL2InboxAddr, crossL2Inbox.Abi, client, client, client)
+	return contract.Transact(transactor, "executeMessage", identifier, L2ToL2CrossDomainMessengerAddress, msg.Payload)
+}
@@ -0,0 +1,156 @@
+package opsimulator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Number of relayed L1 blocks tracked, for reorgs and for the L2 blocks derived from the safe and
+// finalized L1 blocks. Covers the anvil finalization depth of two epochs. Deeper reorgs are not handled
+const maxL1ReorgDepth = 128
+
+// An L1 block whose L1 attributes and deposits were submitted to the L2
+type relayedL1Block struct {
+	hash common.Hash
+
+	// L2 head prior to submitting the deposits. The deposits are included in the following blocks
+	l2Block uint64
+
+	deposits []common.Hash
+}
+
+// Implemented by the anvil instance of the L2, whose blocks are rolled back when the L1 reorgs
+type rollbackChain interface {
+	AnvilRollback(ctx context.Context, depth uint64) error
+	AnvilDropTransaction(ctx context.Context, hash common.Hash) error
+}
+
+func (opSim *OpSimulator) recordRelayedL1Block(number uint64, block relayedL1Block) {
+	opSim.relayedL1BlocksMu.Lock()
+	defer opSim.relayedL1BlocksMu.Unlock()
+
+	opSim.relayedL1Blocks[number] = block
+	if number >= maxL1ReorgDepth {
+		delete(opSim.relayedL1Blocks, number-maxL1ReorgDepth)
+	}
+}
+
+func (opSim *OpSimulator) relayedL1BlockAt(number uint64) (relayedL1Block, bool) {
+	opSim.relayedL1BlocksMu.Lock()
+	defer opSim.relayedL1BlocksMu.Unlock()
+
+	block, ok := opSim.relayedL1Blocks[number]
+	return block, ok
+}
+
+// Removes and returns the relayed L1 blocks after the L1 block, in order
+func (opSim *OpSimulator) forgetRelayedL1BlocksAfter(number uint64) []relayedL1Block {
+	opSim.relayedL1BlocksMu.Lock()
+	defer opSim.relayedL1BlocksMu.Unlock()
+
+	var numbers []uint64
+	for n := range opSim.relayedL1Blocks {
+		if n > number {
+			numbers = append(numbers, n)
+		}
+	}
+	slices.Sort(numbers)
+
+	blocks := make([]relayedL1Block, len(numbers))
+	for i, n := range numbers {
+		blocks[i] = opSim.relayedL1Blocks[n]
+		delete(opSim.relayedL1Blocks, n)
+	}
+	return blocks
+}
+
+// Detects a reorg of the relayed L1 blocks. Like an op-node discarding the unsafe L2 blocks derived from a
+// reorged L1 block, the L2 is rolled back to prior to the deposits of the first reorged block, dropping every
+// transaction since. The cursor is moved to the common ancestor so that the new L1 blocks are relayed thereafter
+func (opSim *OpSimulator) handleL1Reorg(ctx context.Context, head *types.Header) error {
+	relayed := opSim.relayedL1Block.Load()
+	if block, ok := opSim.relayedL1BlockAt(relayed); ok && head.Number.Uint64() == relayed+1 && head.ParentHash == block.hash {
+		return nil
+	}
+
+	ancestor, err := opSim.findL1CommonAncestor(ctx, min(relayed, head.Number.Uint64()))
+	if err != nil {
+		return err
+	}
+	if ancestor == relayed {
+		return nil
+	}
+
+	reorged := opSim.forgetRelayedL1BlocksAfter(ancestor)
+	opSim.log.Warn("l1 reorg detected, rolling back l2", "l1.ancestor", ancestor, "l1.reorged", len(reorged))
+
+	if len(reorged) > 0 {
+		if err := opSim.rollbackL2(ctx, reorged); err != nil {
+			return err
+		}
+	}
+
+	opSim.relayedL1Block.Store(ancestor)
+	return nil
+}
+
+func (opSim *OpSimulator) findL1CommonAncestor(ctx context.Context, from uint64) (uint64, error) {
+	for number := from; ; number-- {
+		block, ok := opSim.relayedL1BlockAt(number)
+		if !ok {
+			return 0, fmt.Errorf("l1 reorg deeper than the %d tracked blocks", maxL1ReorgDepth)
+		}
+
+		header, err := opSim.l1Chain.EthClient().HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch l1 block %d: %w", number, err)
+		}
+		if header.Hash() == block.hash {
+			return number, nil
+		}
+		if number == 0 {
+			return 0, fmt.Errorf("no common ancestor with the l1")
+		}
+	}
+}
+
+// Drops the pending deposits of the reorged L1 blocks and removes the L2 blocks that may include them
+func (opSim *OpSimulator) rollbackL2(ctx context.Context, reorged []relayedL1Block) error {
+	l2Chain, ok := opSim.l2Chain.(rollbackChain)
+	if !ok {
+		return fmt.Errorf("chain %s does not support rollbacks", opSim.l2Chain.Name())
+	}
+
+	opSim.depositsMu.RLock()
+	defer opSim.depositsMu.RUnlock()
+
+	// no block may be sealed between rolling back the blocks and dropping the deposits
+	if opSim.sealsBlocks() {
+		opSim.sealMu.Lock()
+		defer opSim.sealMu.Unlock()
+	}
+
+	for _, block := range reorged {
+		for _, hash := range block.deposits {
+			if err := l2Chain.AnvilDropTransaction(ctx, hash); err != nil {
+				return fmt.Errorf("failed to drop deposit tx %s: %w", hash, err)
+			}
+		}
+	}
+
+	l2Head, err := opSim.l2Chain.EthClient().BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l2 head: %w", err)
+	}
+	if l2Head > reorged[0].l2Block {
+		if err := l2Chain.AnvilRollback(ctx, l2Head-reorged[0].l2Block); err != nil {
+			return fmt.Errorf("failed to roll back l2: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package opsimulator
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayedL1Blocks(t *testing.T) {
+	opSim := &OpSimulator{relayedL1Blocks: make(map[uint64]relayedL1Block)}
+	for number := uint64(0); number < maxL1ReorgDepth+10; number++ {
+		opSim.recordRelayedL1Block(number, relayedL1Block{hash: common.BigToHash(common.Big1), l2Block: number})
+	}
+
+	// only the latest blocks are tracked
+	require.Len(t, opSim.relayedL1Blocks, maxL1ReorgDepth)
+	_, ok := opSim.relayedL1BlockAt(9)
+	require.False(t, ok)
+	_, ok = opSim.relayedL1BlockAt(10)
+	require.True(t, ok)
+
+	reorged := opSim.forgetRelayedL1BlocksAfter(maxL1ReorgDepth + 6)
+	require.Len(t, reorged, 3)
+	for i, block := range reorged {
+		require.Equal(t, uint64(maxL1ReorgDepth+7+i), block.l2Block)
+	}
+	_, ok = opSim.relayedL1BlockAt(maxL1ReorgDepth + 7)
+	require.False(t, ok)
+}
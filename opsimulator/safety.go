@@ -0,0 +1,104 @@
+package opsimulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// resolveSafetyTags replaces the `safe` and `finalized` tags of eth_getBlockByNumber requests with the
+// simulated safe and finalized heads, as anvil does not derive them from the L1. Returns true if any
+// message was modified. Messages whose head cannot be resolved are forwarded unmodified
+func (opSim *OpSimulator) resolveSafetyTags(ctx context.Context, msgs []*jsonRpcMessage) bool {
+	modified := false
+	for _, msg := range msgs {
+		if msg.Method != "eth_getBlockByNumber" {
+			continue
+		}
+
+		var params []json.RawMessage
+		if err := json.Unmarshal(msg.Params, &params); err != nil || len(params) == 0 {
+			continue
+		}
+		var tag rpc.BlockNumber
+		if err := json.Unmarshal(params[0], &tag); err != nil {
+			continue
+		}
+		if tag != rpc.SafeBlockNumber && tag != rpc.FinalizedBlockNumber {
+			continue
+		}
+
+		number, err := opSim.l2HeadAt(ctx, tag)
+		if err != nil {
+			opSim.log.Warn("failed to resolve l2 head", "tag", tag, "err", err)
+			continue
+		}
+
+		params[0], _ = json.Marshal(hexutil.Uint64(number))
+		msg.Params, _ = json.Marshal(params)
+		modified = true
+	}
+	return modified
+}
+
+// l2HeadAt returns the number of the safe or finalized L2 block
+func (opSim *OpSimulator) l2HeadAt(ctx context.Context, tag rpc.BlockNumber) (uint64, error) {
+	latest, err := opSim.l2Chain.EthClient().BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch l2 head: %w", err)
+	}
+
+	safetyConfig := opSim.networkConfig.SafetyConfig
+	if !safetyConfig.FollowL1 {
+		lag := safetyConfig.SafeLag
+		if tag == rpc.FinalizedBlockNumber {
+			lag = safetyConfig.FinalizedLag
+		}
+		return latest - min(lag, latest), nil
+	}
+
+	l1Block, err := opSim.l1Chain.EthClient().HeaderByNumber(ctx, big.NewInt(int64(tag)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch %s l1 block: %w", tag, err)
+	}
+
+	// every L2 block is derived from a relayed L1 block
+	number := l1Block.Number.Uint64()
+	if number >= opSim.relayedL1Block.Load() {
+		return latest, nil
+	}
+
+	// the L2 blocks prior to the deposits of the following L1 block are derived from the L1 block
+	l2Block, ok := opSim.l2BlockBeforeL1Block(number + 1)
+	if !ok {
+		return 0, fmt.Errorf("%s l1 block %d precedes the tracked l1 blocks", tag, number)
+	}
+	return min(l2Block, latest), nil
+}
+
+// Returns the L2 head prior to the deposits of the L1 block. The L1 blocks preceding the tracked
+// blocks are treated as deriving the L2 blocks up to the earliest tracked block
+func (opSim *OpSimulator) l2BlockBeforeL1Block(number uint64) (uint64, bool) {
+	opSim.relayedL1BlocksMu.Lock()
+	defer opSim.relayedL1BlocksMu.Unlock()
+
+	if block, ok := opSim.relayedL1Blocks[number]; ok {
+		return block.l2Block, true
+	}
+
+	var earliest *relayedL1Block
+	var earliestNumber uint64
+	for n, block := range opSim.relayedL1Blocks {
+		if earliest == nil || n < earliestNumber {
+			earliest, earliestNumber = &block, n
+		}
+	}
+	if earliest == nil || number > earliestNumber {
+		return 0, false
+	}
+	return earliest.l2Block, true
+}
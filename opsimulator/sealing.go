@@ -0,0 +1,111 @@
+package opsimulator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum-optimism/supersim/config"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// An L2 mined on an interval is started without mining, its blocks sealed by the op-simulator instead. This
+// lets the deposits of an L1 block lead the next L2 block, as an op-node places them ahead of user transactions
+func (opSim *OpSimulator) sealsBlocks() bool {
+	return opSim.l2Chain.Config().MiningConfig.MiningMode() == config.MiningModeInterval
+}
+
+// Seals a block every block time, in turn with the submission of deposits
+func (opSim *OpSimulator) sealBlocks(ctx context.Context) error {
+	ticker := time.NewTicker(opSim.l2Chain.Config().MiningConfig.IntervalBlockTime())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			opSim.sealMu.Lock()
+			err := opSim.sealBlock(ctx)
+			opSim.sealMu.Unlock()
+			if err != nil && ctx.Err() == nil {
+				opSim.log.Error("failed to seal l2 block", "err", err)
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Seals the pending user transactions into their own block, such that deposits submitted thereafter
+// lead the next block. Called with sealMu held
+func (opSim *OpSimulator) sealPendingTxs(ctx context.Context) error {
+	pending, err := opSim.l2Chain.EthClient().PendingTransactionCount(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pending transactions: %w", err)
+	}
+	if pending == 0 {
+		return nil
+	}
+	return opSim.sealBlock(ctx)
+}
+
+// The L1 origin of the sealed blocks. Like an op-node, every block of an epoch after the first is
+// led by an L1 attributes deposit of the origin, with the sequence number of the block in the epoch
+type epoch struct {
+	origin *types.Header
+	sysCfg eth.SystemConfig
+
+	// sequence number of the last block sealed in the epoch
+	seq uint64
+
+	// the L1 attributes deposit of the next block is submitted, either with the deposits of the origin
+	// for the first block of the epoch or by a seal which failed to mine
+	pending bool
+}
+
+// Seals the next block of the epoch, leading it with the L1 attributes deposit of its sequence
+// number unless already submitted. Called with sealMu held
+func (opSim *OpSimulator) sealBlock(ctx context.Context) error {
+	if opSim.epoch.origin != nil && !opSim.epoch.pending {
+		seq := opSim.epoch.seq + 1
+		l1Info, err := derive.L1InfoDeposit(l1InfoRollupConfig, opSim.epoch.sysCfg, seq, eth.HeaderBlockInfo(opSim.epoch.origin), opSim.epoch.origin.Time)
+		if err != nil {
+			return fmt.Errorf("failed to create l1 info deposit: %w", err)
+		}
+
+		depTx := types.NewTx(l1Info)
+		if err := opSim.l2Chain.EthSendTransaction(ctx, depTx); err != nil {
+			return fmt.Errorf("failed to send l1 info deposit tx %s: %w", depTx.Hash().String(), err)
+		}
+		opSim.epoch.seq = seq
+		opSim.epoch.pending = true
+	}
+
+	if err := opSim.evmMine(ctx); err != nil {
+		return err
+	}
+	opSim.epoch.pending = false
+	return nil
+}
+
+// holdSealing blocks the submission of deposits while transactions sent through the op-simulator are forwarded,
+// such that they do not slip between the pending transactions sealed ahead of the deposits and the deposits
+func (opSim *OpSimulator) holdSealing(msgs []*jsonRpcMessage) func() {
+	for _, msg := range msgs {
+		if msg.Method == "eth_sendRawTransaction" && opSim.sealsBlocks() {
+			opSim.sealMu.RLock()
+			return opSim.sealMu.RUnlock
+		}
+	}
+	return func() {}
+}
+
+func (opSim *OpSimulator) evmMine(ctx context.Context) error {
+	if err := opSim.l2Chain.EthClient().Client().CallContext(ctx, nil, "evm_mine"); err != nil {
+		return fmt.Errorf("failed to mine l2 block: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package opsimulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	// allow connections from any origin, similar to the http CORS handling
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsConn serializes writes as a websocket connection supports only one concurrent writer
+type wsConn struct {
+	*websocket.Conn
+	writeMu sync.Mutex
+}
+
+func (c *wsConn) writeMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.WriteMessage(messageType, data)
+}
+
+func (c *wsConn) writeJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeMessage(websocket.TextMessage, data)
+}
+
+// A frame forwarded upstream, pending until the response to its requests is received
+type wsPendingFrame struct {
+	ids []string
+
+	// releases the sealing held while the frame's transactions are forwarded
+	release func()
+
+	// answers to the requests rejected by the op-simulator, merged into the batch response
+	errResponses []*jsonRpcErrorResponse
+}
+
+// wsPendingFrames tracks the frames forwarded upstream by the ids of their requests
+type wsPendingFrames struct {
+	mu     sync.Mutex
+	byID   map[string]*wsPendingFrame
+	frames map[*wsPendingFrame]struct{}
+}
+
+func newWsPendingFrames() *wsPendingFrames {
+	return &wsPendingFrames{byID: make(map[string]*wsPendingFrame), frames: make(map[*wsPendingFrame]struct{})}
+}
+
+func (p *wsPendingFrames) add(frame *wsPendingFrame) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.frames[frame] = struct{}{}
+	for _, id := range frame.ids {
+		p.byID[id] = frame
+	}
+}
+
+// take removes and returns the frame answered by the response, if any
+func (p *wsPendingFrames) take(data []byte) *wsPendingFrame {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.frames) == 0 {
+		return nil
+	}
+
+	for _, id := range wsResponseIDs(data) {
+		frame, ok := p.byID[id]
+		if !ok {
+			continue
+		}
+		for _, id := range frame.ids {
+			if p.byID[id] == frame {
+				delete(p.byID, id)
+			}
+		}
+		delete(p.frames, frame)
+		return frame
+	}
+	return nil
+}
+
+// releaseAll releases the frames left unanswered, including those whose ids were
+// reused by a later frame, once the connection is closed
+func (p *wsPendingFrames) releaseAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for frame := range p.frames {
+		frame.release()
+	}
+	clear(p.frames)
+	clear(p.byID)
+}
+
+func wsMessageID(id json.RawMessage) string {
+	return string(bytes.TrimSpace(id))
+}
+
+// wsResponseIDs returns the ids of a response or batch of responses. Subscription notifications carry no id
+func wsResponseIDs(data []byte) []string {
+	type response struct {
+		ID json.RawMessage `json:"id"`
+	}
+
+	var responses []response
+	if isJsonRpcBatch(data) {
+		if err := json.Unmarshal(data, &responses); err != nil {
+			return nil
+		}
+	} else {
+		var resp response
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil
+		}
+		responses = append(responses, resp)
+	}
+
+	var ids []string
+	for _, resp := range responses {
+		if len(resp.ID) > 0 {
+			ids = append(ids, wsMessageID(resp.ID))
+		}
+	}
+	return ids
+}
+
+// handleWebSocket proxies a websocket connection to the underlying chain. Subscriptions
+// are passed through while frames are inspected and rewritten like http requests
+func (opSim *OpSimulator) handleWebSocket(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	upstreamConn, _, err := websocket.DefaultDialer.DialContext(r.Context(), opSim.l2Chain.WSEndpoint(), nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to connect to chain websocket: %s", err), http.StatusBadGateway)
+		return
+	}
+	upstream := &wsConn{Conn: upstreamConn}
+	defer upstream.Close()
+
+	clientConn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		opSim.log.Error("failed to upgrade websocket connection", "err", err)
+		return
+	}
+	client := &wsConn{Conn: clientConn}
+	defer client.Close()
+
+	pending := newWsPendingFrames()
+	defer pending.releaseAll()
+
+	// upstream -> client
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			messageType, data, err := upstream.ReadMessage()
+			if err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					opSim.log.Debug("upstream websocket closed", "err", err)
+				}
+				client.Close()
+				return
+			}
+			if frame := pending.take(data); frame != nil {
+				frame.release()
+				if data, err = mergeBatchResponse(data, frame.errResponses); err != nil {
+					opSim.log.Debug("failed to merge batch response", "err", err)
+					return
+				}
+			}
+			if err := client.writeMessage(messageType, data); err != nil {
+				opSim.log.Debug("failed to write to client websocket", "err", err)
+				return
+			}
+		}
+	}()
+
+	// client -> upstream
+	for {
+		messageType, data, err := client.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				opSim.log.Debug("client websocket closed", "err", err)
+			}
+			break
+		}
+
+		if err := opSim.handleWebSocketMessage(ctx, client, upstream, pending, messageType, data); err != nil {
+			opSim.log.Debug("failed to proxy websocket message", "err", err)
+			break
+		}
+	}
+
+	upstream.Close()
+	<-done
+}
+
+func (opSim *OpSimulator) handleWebSocketMessage(ctx context.Context, client, upstream *wsConn, pending *wsPendingFrames, messageType int, data []byte) error {
+	msgs, isBatch, err := readJsonMessages(bytes.NewReader(data))
+	if err != nil {
+		// let the chain respond with the appropriate parse error
+		return upstream.writeMessage(messageType, data)
+	}
+
+	if opSim.resolveSafetyTags(ctx, msgs) {
+		if data, err = writeJsonMessages(msgs, isBatch); err != nil {
+			return fmt.Errorf("failed to encode JSON-RPC message: %w", err)
+		}
+	}
+
+	validMsgs, errResponses := opSim.checkJsonRpcMessages(ctx, msgs)
+	if len(errResponses) > 0 && !isBatch {
+		if errResponses[0].notification {
+			return nil
+		}
+		return client.writeJSON(errResponses[0])
+	}
+
+	// The batch response must be delivered as a single frame, so the valid subset is
+	// forwarded alone, its response merged with the error responses once received
+	if len(errResponses) > 0 {
+		if data, err = writeJsonMessages(validMsgs, true); err != nil {
+			return fmt.Errorf("failed to encode JSON-RPC batch: %w", err)
+		}
+	}
+
+	frame := &wsPendingFrame{release: opSim.holdSealing(validMsgs), errResponses: errResponses}
+	for _, msg := range validMsgs {
+		if !msg.isNotification() {
+			frame.ids = append(frame.ids, wsMessageID(msg.ID))
+		}
+	}
+
+	// without any request expecting a response, nothing is awaited from upstream
+	if len(frame.ids) == 0 {
+		defer frame.release()
+		if len(validMsgs) > 0 {
+			if err := upstream.writeMessage(messageType, data); err != nil {
+				return err
+			}
+		}
+		responses, err := mergeBatchResponse([]byte("[]"), errResponses)
+		if err != nil || string(responses) == "[]" {
+			return err
+		}
+		return client.writeMessage(websocket.TextMessage, responses)
+	}
+
+	// registered ahead of the write, as the response may be received before it returns
+	pending.add(frame)
+	return upstream.writeMessage(messageType, data)
+}
+
+// mergeBatchResponse appends the error responses to the batch response received from upstream
+func mergeBatchResponse(data []byte, errResponses []*jsonRpcErrorResponse) ([]byte, error) {
+	if len(errResponses) == 0 {
+		return data, nil
+	}
+
+	var responses []json.RawMessage
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, fmt.Errorf("failed to decode batch response: %w", err)
+	}
+	for _, errResponse := range errResponses {
+		if errResponse.notification {
+			continue
+		}
+		response, err := json.Marshal(errResponse)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode JSON-RPC error: %w", err)
+		}
+		responses = append(responses, response)
+	}
+	return json.Marshal(responses)
+}
@@ -0,0 +1,107 @@
+package opsimulator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	"github.com/ethereum-optimism/supersim/testutils"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketProxy(t *testing.T) {
+	// upstream echoes every frame back to the client
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}))
+	defer upstream.Close()
+
+	chain := &testutils.MockChain{WSEndpointUrl: "ws" + strings.TrimPrefix(upstream.URL, "http")}
+	opSim := &OpSimulator{log: testlog.Logger(t, log.LevelInfo), l2Chain: chain}
+
+	server := httptest.NewServer(opSim.handler(nil, context.Background()))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// messages are proxied to the chain
+	req := `{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, req, string(data))
+
+	// invalid transactions are rejected by the simulator
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":2,"method":"eth_sendRawTransaction","params":[]}`)))
+	_, data, err = conn.ReadMessage()
+	require.NoError(t, err)
+
+	var resp jsonRpcErrorResponse
+	require.NoError(t, json.Unmarshal(data, &resp))
+	require.Equal(t, json.RawMessage("2"), resp.ID)
+	require.Equal(t, jsonRpcInvalidParamsErrorCode, resp.Error.Code)
+
+	// rejected notifications are not answered, the next frame answers the following request
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"eth_sendRawTransaction","params":[]}`)))
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	_, data, err = conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, req, string(data))
+
+	// the valid subset of a batch is forwarded over the socket, the response merged with the rejections
+	batch := `[{"jsonrpc":"2.0","id":3,"method":"eth_subscribe","params":["newHeads"]},{"jsonrpc":"2.0","id":4,"method":"eth_sendRawTransaction","params":[]}]`
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(batch)))
+	_, data, err = conn.ReadMessage()
+	require.NoError(t, err)
+
+	var responses []jsonRpcErrorResponse
+	require.NoError(t, json.Unmarshal(data, &responses))
+	require.Len(t, responses, 2)
+	require.Equal(t, json.RawMessage("3"), responses[0].ID)
+	require.Nil(t, responses[0].Error)
+	require.Equal(t, json.RawMessage("4"), responses[1].ID)
+	require.Equal(t, jsonRpcInvalidParamsErrorCode, responses[1].Error.Code)
+}
+
+func TestWsPendingFrames(t *testing.T) {
+	pending := newWsPendingFrames()
+
+	var released []int
+	first := &wsPendingFrame{ids: []string{"1", "2"}, release: func() { released = append(released, 1) }}
+	second := &wsPendingFrame{ids: []string{"3"}, release: func() { released = append(released, 2) }}
+	pending.add(first)
+	pending.add(second)
+
+	// subscription notifications and unknown ids do not answer a frame
+	require.Nil(t, pending.take([]byte(`{"jsonrpc":"2.0","method":"eth_subscription","params":{}}`)))
+	require.Nil(t, pending.take([]byte(`{"jsonrpc":"2.0","id":5,"result":"0x1"}`)))
+
+	// the batch response answers the frame of its requests
+	require.Equal(t, first, pending.take([]byte(`[{"jsonrpc":"2.0","id":2,"result":"0x1"},{"jsonrpc":"2.0","id":1,"result":"0x1"}]`)))
+	require.Nil(t, pending.take([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`)))
+
+	// unanswered frames are released once the connection closes
+	pending.releaseAll()
+	require.Equal(t, []int{2}, released)
+}
@@ -0,0 +1,389 @@
+package opsimulator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	opbindings "github.com/ethereum-optimism/optimism/op-node/bindings"
+	opbindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	registry "github.com/ethereum-optimism/superchain-registry/superchain"
+	"github.com/ethereum-optimism/supersim/config"
+	"github.com/ethereum-optimism/supersim/hdaccount"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+// TODO: replace with the monorepo bindings once FaultDisputeGame is available outside of op-e2e
+const faultDisputeGameResolveABI = `[{"type":"function","name":"resolveClaim","inputs":[{"name":"_claimIndex","type":"uint256"},{"name":"_numToResolve","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"},{"type":"function","name":"resolve","inputs":[],"outputs":[{"name":"status_","type":"uint8"}],"stateMutability":"nonpayable"}]`
+
+var faultDisputeGameABI, _ = abi.JSON(strings.NewReader(faultDisputeGameResolveABI))
+
+// Withdrawals from every L2 are submitted to the same L1 from a shared account. Sends are serialized
+// so that concurrently running simulators do not race on the account nonce, receipts awaited apart
+var l1TransactorMu sync.Mutex
+
+// Instant withdrawals of every L2 share the same clock. Warps are serialized, such that a withdrawal
+// already finalizable, possibly through the warp of another, does not warp again
+var withdrawalWarpMu sync.Mutex
+
+// A dispute game proposing the output root at a specific L2 block
+type outputProposal struct {
+	gameIndex *big.Int
+}
+
+// A proposal of an L2 block, in flight until done is closed
+type pendingOutputProposal struct {
+	done     chan struct{}
+	proposal *outputProposal
+	err      error
+}
+
+// Output proposals by L2 block, shared by the withdrawals finalized concurrently
+type outputProposals struct {
+	mu      sync.Mutex
+	byBlock map[uint64]*pendingOutputProposal
+}
+
+// get returns the proposal of the L2 block, proposing the output when absent. Only withdrawals of the same
+// L2 block wait on the proposal in flight, and a failed proposal is retried by the next withdrawal
+func (p *outputProposals) get(ctx context.Context, l2Block uint64, propose func() (*outputProposal, error)) (*outputProposal, error) {
+	p.mu.Lock()
+	pending, ok := p.byBlock[l2Block]
+	if !ok {
+		pending = &pendingOutputProposal{done: make(chan struct{})}
+		p.byBlock[l2Block] = pending
+	}
+	p.mu.Unlock()
+
+	if ok {
+		select {
+		case <-pending.done:
+			if pending.err != nil {
+				return nil, pending.err
+			}
+			return pending.proposal, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	pending.proposal, pending.err = propose()
+	if pending.err != nil {
+		p.mu.Lock()
+		delete(p.byBlock, l2Block)
+		p.mu.Unlock()
+	}
+	close(pending.done)
+	return pending.proposal, pending.err
+}
+
+// Proposes output roots, proves and finalizes every withdrawal initiated on this chain. Withdrawals are
+// finalized concurrently, such that the finalization delay of one does not hold back the others
+func (opSim *OpSimulator) finalizeWithdrawals(ctx context.Context) error {
+	l1Addresses := opSim.L2Config.L1Addresses
+	if l1Addresses == nil || l1Addresses.DisputeGameFactoryProxy == (registry.Address{}) {
+		return errors.New("withdrawals require a DisputeGameFactory deployment on the L1")
+	}
+
+	withdrawalConfig := opSim.networkConfig.WithdrawalConfig
+	secrets := opSim.l1Chain.Config().SecretsConfig
+	hdAccountStore, err := hdaccount.NewHdAccountStore(secrets.Mnemonic, secrets.DerivationPath)
+	if err != nil {
+		return fmt.Errorf("failed to create hd account store: %w", err)
+	}
+	privateKey, err := hdAccountStore.DerivePrivateKeyAt(withdrawalConfig.AccountIndex)
+	if err != nil {
+		return fmt.Errorf("failed to derive withdrawal account private key: %w", err)
+	}
+
+	if withdrawalConfig.FinalizationDelay == 0 && opSim.warp == nil {
+		return errors.New("finalizing withdrawals instantly requires a warp of every chain")
+	}
+
+	messagePasser, err := opbindings.NewL2ToL1MessagePasserFilterer(predeploys.L2ToL1MessagePasserAddr, nil)
+	if err != nil {
+		return fmt.Errorf("failed to bind L2ToL1MessagePasser: %w", err)
+	}
+
+	logCh := make(chan types.Log)
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{predeploys.L2ToL1MessagePasserAddr},
+		Topics:    [][]common.Hash{{withdrawals.MessagePassedTopic}},
+	}
+	go opSim.followLogs(ctx, opSim.l2Chain, query, nil, logCh)
+
+	// withdrawals in the same L2 block share the same output proposal
+	proposals := &outputProposals{byBlock: make(map[uint64]*pendingOutputProposal)}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case log := <-logCh:
+			if log.Removed {
+				continue
+			}
+
+			ev, err := messagePasser.ParseMessagePassed(log)
+			if err != nil {
+				opSim.log.Error("failed to parse withdrawal", "err", err)
+				continue
+			}
+
+			withdrawalHash := common.Hash(ev.WithdrawalHash).String()
+			opSim.log.Debug("received withdrawal", "withdrawal.hash", withdrawalHash, "tx.hash", log.TxHash.String())
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := opSim.finalizeWithdrawal(ctx, privateKey, &withdrawalConfig, proposals, &log); err != nil {
+					opSim.log.Error("failed to finalize withdrawal", "withdrawal.hash", withdrawalHash, "err", err)
+					return
+				}
+				opSim.log.Debug("finalized withdrawal", "withdrawal.hash", withdrawalHash)
+			}()
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (opSim *OpSimulator) finalizeWithdrawal(ctx context.Context, privateKey *ecdsa.PrivateKey, withdrawalConfig *config.WithdrawalConfig, proposals *outputProposals, log *types.Log) error {
+	l1Client, l2Client := opSim.l1Chain.EthClient(), opSim.l2Chain.EthClient()
+	l1Addresses := opSim.L2Config.L1Addresses
+
+	portal, err := opbindingspreview.NewOptimismPortal2(common.Address(l1Addresses.OptimismPortalProxy), l1Client)
+	if err != nil {
+		return fmt.Errorf("failed to bind OptimismPortal2: %w", err)
+	}
+	factory, err := opbindings.NewDisputeGameFactory(common.Address(l1Addresses.DisputeGameFactoryProxy), l1Client)
+	if err != nil {
+		return fmt.Errorf("failed to bind DisputeGameFactory: %w", err)
+	}
+
+	transactor, err := bind.NewKeyedTransactorWithChainID(privateKey, new(big.Int).SetUint64(opSim.l1Chain.ChainID()))
+	if err != nil {
+		return fmt.Errorf("failed to create withdrawal transactor: %w", err)
+	}
+	transactor.Context = ctx
+
+	// Withdrawal proof against the L2 block including the withdrawal
+	l2BlockNumber := new(big.Int).SetUint64(log.BlockNumber)
+	params, err := withdrawals.ProveWithdrawalParametersForBlock(ctx, gethclient.New(l2Client.Client()), l2Client, l2Client, log.TxHash, l2BlockNumber, nil)
+	if err != nil {
+		return fmt.Errorf("failed to generate withdrawal proof: %w", err)
+	}
+
+	proposal, err := proposals.get(ctx, log.BlockNumber, func() (*outputProposal, error) {
+		return opSim.proposeOutput(ctx, transactor, portal, factory, l2BlockNumber, &params.OutputRootProof)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to propose output: %w", err)
+	}
+
+	withdrawalTx := opbindingspreview.TypesWithdrawalTransaction{
+		Nonce:    params.Nonce,
+		Sender:   params.Sender,
+		Target:   params.Target,
+		Value:    params.Value,
+		GasLimit: params.GasLimit,
+		Data:     params.Data,
+	}
+	outputRootProof := opbindingspreview.TypesOutputRootProof{
+		Version:                  params.OutputRootProof.Version,
+		StateRoot:                params.OutputRootProof.StateRoot,
+		MessagePasserStorageRoot: params.OutputRootProof.MessagePasserStorageRoot,
+		LatestBlockhash:          params.OutputRootProof.LatestBlockhash,
+	}
+
+	proveReceipt, err := opSim.sendL1Tx(ctx, func() (*types.Transaction, error) {
+		return portal.ProveWithdrawalTransaction(transactor, withdrawalTx, proposal.gameIndex, outputRootProof, params.WithdrawalProof)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prove withdrawal: %w", err)
+	}
+	proveHeader, err := l1Client.HeaderByNumber(ctx, proveReceipt.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("failed to fetch withdrawal proof block: %w", err)
+	}
+
+	if err := opSim.waitForWithdrawalFinalization(ctx, portal, withdrawalConfig, proveHeader.Time); err != nil {
+		return err
+	}
+
+	if _, err := opSim.sendL1Tx(ctx, func() (*types.Transaction, error) {
+		return portal.FinalizeWithdrawalTransaction(transactor, withdrawalTx)
+	}); err != nil {
+		return fmt.Errorf("failed to finalize withdrawal: %w", err)
+	}
+
+	return nil
+}
+
+// Creates and resolves a dispute game of the respected game type for the L2 output root
+func (opSim *OpSimulator) proposeOutput(ctx context.Context, transactor *bind.TransactOpts, portal *opbindingspreview.OptimismPortal2, factory *opbindings.DisputeGameFactory, l2BlockNumber *big.Int, proof *opbindings.TypesOutputRootProof) (*outputProposal, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	gameType, err := portal.RespectedGameType(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query respected game type: %w", err)
+	}
+	bond, err := factory.InitBonds(callOpts, gameType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query init bond: %w", err)
+	}
+
+	outputRoot := eth.OutputRoot(&eth.OutputV0{
+		StateRoot:                proof.StateRoot,
+		MessagePasserStorageRoot: proof.MessagePasserStorageRoot,
+		BlockHash:                proof.LatestBlockhash,
+	})
+
+	createOpts := *transactor
+	createOpts.Value = bond
+	receipt, err := opSim.sendL1Tx(ctx, func() (*types.Transaction, error) {
+		return factory.Create(&createOpts, gameType, outputRoot, common.BigToHash(l2BlockNumber).Bytes())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dispute game: %w", err)
+	}
+
+	var gameProxy common.Address
+	for _, log := range receipt.Logs {
+		if ev, err := factory.ParseDisputeGameCreated(*log); err == nil {
+			gameProxy = ev.DisputeProxy
+			break
+		}
+	}
+	if gameProxy == (common.Address{}) {
+		return nil, errors.New("dispute game creation event not found")
+	}
+
+	// The created game is among the latest as of the creation block, which may include the games of other chains
+	gameCount, err := factory.GameCount(&bind.CallOpts{Context: ctx, BlockNumber: receipt.BlockNumber})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query game count: %w", err)
+	}
+	var gameIndex *big.Int
+	for index := new(big.Int).Sub(gameCount, common.Big1); index.Sign() >= 0; index = new(big.Int).Sub(index, common.Big1) {
+		game, err := factory.GameAtIndex(callOpts, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query game at index %d: %w", index, err)
+		}
+		if game.Proxy == gameProxy {
+			gameIndex = index
+			break
+		}
+	}
+	if gameIndex == nil {
+		return nil, errors.New("created dispute game not found")
+	}
+
+	// Resolve the root claim in favor of the proposer. The respected game types used in
+	// local deployments have no clock duration and are resolvable immediately
+	gameContract := bind.NewBoundContract(gameProxy, faultDisputeGameABI, opSim.l1Chain.EthClient(), opSim.l1Chain.EthClient(), opSim.l1Chain.EthClient())
+	if _, err := opSim.sendL1Tx(ctx, func() (*types.Transaction, error) {
+		return gameContract.Transact(transactor, "resolveClaim", common.Big0, common.Big0)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to resolve root claim: %w", err)
+	}
+	if _, err := opSim.sendL1Tx(ctx, func() (*types.Transaction, error) {
+		return gameContract.Transact(transactor, "resolve")
+	}); err != nil {
+		return nil, fmt.Errorf("failed to resolve dispute game: %w", err)
+	}
+
+	opSim.log.Debug("proposed output", "l2.block", l2BlockNumber, "output.root", common.Hash(outputRoot).String(), "game.index", gameIndex)
+	return &outputProposal{gameIndex: gameIndex}, nil
+}
+
+// Waits for, or in instant mode warps every chain past, the portal's proof maturity and dispute game finality delays
+func (opSim *OpSimulator) waitForWithdrawalFinalization(ctx context.Context, portal *opbindingspreview.OptimismPortal2, withdrawalConfig *config.WithdrawalConfig, provenAt uint64) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+	proofMaturityDelay, err := portal.ProofMaturityDelaySeconds(callOpts)
+	if err != nil {
+		return fmt.Errorf("failed to query proof maturity delay: %w", err)
+	}
+	finalityDelay, err := portal.DisputeGameFinalityDelaySeconds(callOpts)
+	if err != nil {
+		return fmt.Errorf("failed to query dispute game finality delay: %w", err)
+	}
+
+	// Both delays are exclusive bounds
+	requiredDelay := proofMaturityDelay.Uint64()
+	if finalityDelay.Uint64() > requiredDelay {
+		requiredDelay = finalityDelay.Uint64()
+	}
+	requiredDelay++
+
+	if withdrawalConfig.FinalizationDelay == 0 {
+		return opSim.warpPast(ctx, provenAt+requiredDelay)
+	}
+
+	delay := withdrawalConfig.FinalizationDelay
+	if delay < requiredDelay {
+		opSim.log.Warn("withdrawal delay is shorter than the portal delays", "delay", delay, "required", requiredDelay)
+		delay = requiredDelay
+	}
+
+	select {
+	case <-time.After(time.Duration(delay) * time.Second):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Warps every chain such that the L1 reaches the timestamp, unless already reached. The
+// L1 alone is not warped, keeping the clocks of the chains aligned
+func (opSim *OpSimulator) warpPast(ctx context.Context, timestamp uint64) error {
+	withdrawalWarpMu.Lock()
+	defer withdrawalWarpMu.Unlock()
+
+	head, err := opSim.l1Chain.EthClient().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l1 head: %w", err)
+	}
+	if head.Time >= timestamp {
+		return nil
+	}
+
+	if err := opSim.warp(ctx, timestamp-head.Time); err != nil {
+		return fmt.Errorf("failed to warp time: %w", err)
+	}
+	return nil
+}
+
+// Submits an L1 transaction and waits for a successful receipt
+func (opSim *OpSimulator) sendL1Tx(ctx context.Context, send func() (*types.Transaction, error)) (*types.Receipt, error) {
+	// the transactor assigns the pending nonce, which accounts for the transactions sent prior
+	l1TransactorMu.Lock()
+	tx, err := send()
+	l1TransactorMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := bind.WaitMined(ctx, opSim.l1Chain.EthClient(), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for tx %s: %w", tx.Hash().String(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("tx %s reverted", tx.Hash().String())
+	}
+	return receipt, nil
+}
@@ -0,0 +1,54 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	opsimulator "github.com/ethereum-optimism/supersim/opsimulator"
+)
+
+// AddDependency adds the dependency chain to the dependency set of the L2 chain, which
+// from then on accepts executing messages that reference the dependency chain
+func (o *Orchestrator) AddDependency(ctx context.Context, chainID, dependencyChainID uint64) error {
+	opSim, err := o.dependencySetOpSim(chainID, dependencyChainID)
+	if err != nil {
+		return err
+	}
+
+	// the op-simulator tasks are restarted alongside a crashed chain as well. The lock is
+	// released once the deposit is submitted, not blocking restarts on its inclusion
+	o.supervisor.restartMu.Lock()
+	wait, err := opSim.AddToDependencySet(ctx, dependencyChainID)
+	o.supervisor.restartMu.Unlock()
+	if err != nil {
+		return err
+	}
+	return wait(ctx)
+}
+
+// RemoveDependency removes the dependency chain from the dependency set of the L2 chain
+func (o *Orchestrator) RemoveDependency(ctx context.Context, chainID, dependencyChainID uint64) error {
+	opSim, err := o.dependencySetOpSim(chainID, dependencyChainID)
+	if err != nil {
+		return err
+	}
+
+	o.supervisor.restartMu.Lock()
+	wait, err := opSim.RemoveFromDependencySet(ctx, dependencyChainID)
+	o.supervisor.restartMu.Unlock()
+	if err != nil {
+		return err
+	}
+	return wait(ctx)
+}
+
+func (o *Orchestrator) dependencySetOpSim(chainID, dependencyChainID uint64) (*opsimulator.OpSimulator, error) {
+	opSim, ok := o.L2OpSims[chainID]
+	if !ok {
+		return nil, fmt.Errorf("no l2 chain with chain id %d", chainID)
+	}
+	if _, ok := o.L2OpSims[dependencyChainID]; !ok {
+		return nil, fmt.Errorf("no l2 chain with chain id %d", dependencyChainID)
+	}
+	return opSim, nil
+}
@@ -7,44 +7,49 @@ import (
 	"os"
 	"strings"
 
+	opgenesis "github.com/ethereum-optimism/optimism/op-chain-ops/genesis"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
 	registry "github.com/ethereum-optimism/superchain-registry/superchain"
+	"github.com/ethereum-optimism/supersim/bindings"
 	"github.com/ethereum-optimism/supersim/config"
+	"github.com/ethereum-optimism/supersim/forkcache"
+	"github.com/ethereum-optimism/supersim/genesis"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 )
 
-const blockTime = 2
-
-func NetworkConfigFromForkCLIConfig(log log.Logger, envPrefix string, forkConfig *config.ForkCLIConfig) (config.NetworkConfig, error) {
-	superchain := registry.Superchains[forkConfig.Network]
+// The L1 is forked at the fork height or timestamp, and the L2 chains are forked with the mining configuration,
+// each at the latest block aligned with the L1 fork block unless overridden. When a fork cache is set, every
+// network is reached through the cache, including by the forked chains
+func NetworkConfigFromForkCLIConfig(log log.Logger, envPrefix string, forkConfig *config.ForkCLIConfig, l2MiningConfig config.MiningConfig, cache *forkcache.Cache) (config.NetworkConfig, error) {
 	networkConfig := config.NetworkConfig{}
+	superchain, ok := forkConfig.SuperchainRegistry().Network(forkConfig.Network)
+	if !ok {
+		return networkConfig, fmt.Errorf("unrecognized superchain network %s", forkConfig.Network)
+	}
 
 	// L1
-	l1Client, err := ethclient.Dial(superchain.Config.L1.PublicRPC)
+	l1RpcUrl, err := forkRpcUrl(log, envPrefix, forkConfig.Network, superchain.L1.PublicRPC, cache)
 	if err != nil {
-		return networkConfig, fmt.Errorf("failed to dial l1 public rpc: %w", err)
-	}
-
-	var l1ForkHeight *big.Int
-	if forkConfig.L1ForkHeight > 0 {
-		l1ForkHeight = new(big.Int).SetUint64(forkConfig.L1ForkHeight)
+		return networkConfig, err
 	}
-	l1Header, err := l1Client.HeaderByNumber(context.Background(), l1ForkHeight)
+	l1Client, err := ethclient.Dial(l1RpcUrl)
 	if err != nil {
-		return networkConfig, fmt.Errorf("failed to retrieve L1 header: %w", err)
+		return networkConfig, fmt.Errorf("failed to dial l1 rpc: %w", err)
 	}
 
-	l1RpcUrl := superchain.Config.L1.PublicRPC
-	if url, ok := os.LookupEnv(fmt.Sprintf("%s_RPC_URL_%s", envPrefix, strings.ToUpper(forkConfig.Network))); ok {
-		log.Info("detected rpc override", "name", forkConfig.Network, "url", url)
-		l1RpcUrl = url
+	l1Header, err := l1ForkHeader(context.Background(), l1Client, forkConfig)
+	if err != nil {
+		return networkConfig, err
 	}
 
 	networkConfig.L1Config = config.ChainConfig{
 		Name:          forkConfig.Network,
-		ChainID:       superchain.Config.L1.ChainID,
+		ChainID:       superchain.L1.ChainID,
 		SecretsConfig: config.DefaultSecretsConfig,
 		ForkConfig: &config.ForkConfig{
 			RPCUrl:      l1RpcUrl,
@@ -54,20 +59,26 @@ func NetworkConfigFromForkCLIConfig(log log.Logger, envPrefix string, forkConfig
 
 	// L2s
 	for _, chain := range forkConfig.Chains {
-		chainCfg := config.OPChainByName(superchain, chain)
+		chainCfg := superchain.ChainByName(chain)
 		if chainCfg == nil {
-			return networkConfig, fmt.Errorf("unrecoginized chain %s. superchain %s", chain, superchain.Superchain)
+			return networkConfig, fmt.Errorf("unrecoginized chain %s. superchain %s", chain, superchain.Name)
 		}
 
-		l2ForkHeight, err := latestL2HeightFromL1Header(chainCfg, l1Header)
+		rpcUrl, err := forkRpcUrl(log, envPrefix, chainCfg.Chain, chainCfg.PublicRPC, cache)
 		if err != nil {
-			return networkConfig, fmt.Errorf("failed to find right l2 height: %w", err)
+			return networkConfig, err
 		}
 
-		rpcUrl := chainCfg.PublicRPC
-		if url, ok := os.LookupEnv(fmt.Sprintf("%s_RPC_URL_%s", envPrefix, strings.ToUpper(chainCfg.Chain))); ok {
-			log.Info("detected rpc override", "name", chainCfg.Chain, "url", url)
-			rpcUrl = url
+		l2ForkHeight, ok := forkConfig.L2ForkHeights[chain]
+		if ok {
+			if err := checkL2ForkHeight(context.Background(), chainCfg, rpcUrl, l2ForkHeight, l1Header); err != nil {
+				return networkConfig, err
+			}
+		} else {
+			l2ForkHeight, err = alignedL2Height(context.Background(), log, chainCfg, rpcUrl, l1Header)
+			if err != nil {
+				return networkConfig, fmt.Errorf("failed to find right l2 height: %w", err)
+			}
 		}
 
 		networkConfig.L2Configs = append(networkConfig.L2Configs, config.ChainConfig{
@@ -78,41 +89,229 @@ func NetworkConfigFromForkCLIConfig(log log.Logger, envPrefix string, forkConfig
 				RPCUrl:      rpcUrl,
 				BlockNumber: l2ForkHeight,
 			},
+			MiningConfig: l2MiningConfig,
 			L2Config: &config.L2Config{
-				L1ChainID:   superchain.Config.L1.ChainID,
-				L1Addresses: registry.Addresses[chainCfg.ChainID],
+				L1ChainID:   superchain.L1.ChainID,
+				L1Addresses: &chainCfg.Addresses,
 			},
 		})
 	}
 
+	if forkConfig.InteropEnabled {
+		enableForkedInterop(&networkConfig)
+	}
+
 	return networkConfig, nil
 }
 
-func latestL2HeightFromL1Header(l2Cfg *registry.ChainConfig, l1Header *types.Header) (uint64, error) {
+// Every forked chain depends on every other forked chain
+func enableForkedInterop(networkConfig *config.NetworkConfig) {
+	for i := range networkConfig.L2Configs {
+		chainCfg := &networkConfig.L2Configs[i]
+		chainCfg.ForkConfig.InteropPredeploys = true
+
+		chainCfg.L2Config.DependencySet = nil
+		for _, other := range networkConfig.L2Configs {
+			if other.ChainID != chainCfg.ChainID {
+				chainCfg.L2Config.DependencySet = append(chainCfg.L2Config.DependencySet, other.ChainID)
+			}
+		}
+	}
+}
+
+// Implemented by the anvil instance of a forked chain, whose predeploys are set in place
+type predeployChain interface {
+	EthClient() *ethclient.Client
+	AnvilSetCode(ctx context.Context, account common.Address, code []byte) error
+	AnvilSetStorageAt(ctx context.Context, account common.Address, slot, value common.Hash) error
+}
+
+// setInteropPredeploys sets the interop predeploys on the forked chain and upgrades the L1Block
+// implementation in place to L1BlockInterop, retaining the L1Block storage of the chain
+func setInteropPredeploys(ctx context.Context, chain predeployChain) error {
+	allocs, err := genesis.InteropPredeployAllocs()
+	if err != nil {
+		return fmt.Errorf("failed to read interop predeploys: %w", err)
+	}
+	l1BlockCode, err := genesis.L1BlockInteropCode()
+	if err != nil {
+		return fmt.Errorf("failed to read L1BlockInterop code: %w", err)
+	}
+	return writeInteropPredeploys(ctx, chain, allocs, l1BlockCode)
+}
+
+func writeInteropPredeploys(ctx context.Context, chain predeployChain, allocs types.GenesisAlloc, l1BlockCode []byte) error {
+	for addr, account := range allocs {
+		if err := chain.AnvilSetCode(ctx, addr, account.Code); err != nil {
+			return fmt.Errorf("failed to set code of %s: %w", addr, err)
+		}
+		for slot, value := range account.Storage {
+			if err := chain.AnvilSetStorageAt(ctx, addr, slot, value); err != nil {
+				return fmt.Errorf("failed to set storage of %s: %w", addr, err)
+			}
+		}
+	}
+
+	implSlot, err := chain.EthClient().StorageAt(ctx, predeploys.L1BlockAddr, opgenesis.ImplementationSlot, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read L1Block implementation: %w", err)
+	}
+	if err := chain.AnvilSetCode(ctx, common.BytesToAddress(implSlot), l1BlockCode); err != nil {
+		return fmt.Errorf("failed to set L1BlockInterop code: %w", err)
+	}
+	return nil
+}
+
+// forkRpcUrl returns the endpoint of the network, the public rpc unless overridden by the environment
+func forkRpcUrl(log log.Logger, envPrefix, name, publicRpcUrl string, cache *forkcache.Cache) (string, error) {
+	rpcUrl := publicRpcUrl
+	if url, ok := os.LookupEnv(fmt.Sprintf("%s_RPC_URL_%s", envPrefix, strings.ToUpper(name))); ok {
+		log.Info("detected rpc override", "name", name, "url", url)
+		rpcUrl = url
+	}
+
+	if cache == nil {
+		return rpcUrl, nil
+	}
+	cachedRpcUrl, err := cache.Proxy(name, rpcUrl)
+	if err != nil {
+		return "", fmt.Errorf("failed to cache rpc of %s: %w", name, err)
+	}
+	return cachedRpcUrl, nil
+}
+
+// alignedL2Height returns the latest L2 block with a timestamp not exceeding that of the L1 block. The height is
+// estimated from the registry block time and verified against the chain. Should the block time have changed over
+// the history of the chain, the height is instead found with a binary search over the timestamps of the headers
+func alignedL2Height(ctx context.Context, log log.Logger, l2Cfg *registry.ChainConfig, rpcUrl string, l1Header *types.Header) (uint64, error) {
 	if l1Header.Time < l2Cfg.Genesis.L2Time {
 		return 0, fmt.Errorf("l1 height precedes l2 genesis time for chain %s", l2Cfg.Chain)
 	}
 
-	l2Client, err := ethclient.Dial(l2Cfg.PublicRPC)
+	l2Client, err := ethclient.Dial(rpcUrl)
 	if err != nil {
-		return 0, fmt.Errorf("failed to dial l2 public rpc: %w", err)
+		return 0, fmt.Errorf("failed to dial l2 rpc: %w", err)
 	}
+	defer l2Client.Close()
 
-	latestHeader, err := l2Client.HeaderByNumber(context.Background(), nil)
+	latestHeader, err := l2Client.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return 0, fmt.Errorf("failed to query latest header: %w", err)
 	}
+	if latestHeader.Time <= l1Header.Time {
+		return latestHeader.Number.Uint64(), nil
+	}
+
+	// the fork height is within [low, high)
+	low, high := l2Cfg.Genesis.L2.Number, latestHeader.Number.Uint64()
+	headerTime := func(number uint64) (uint64, error) {
+		header, err := l2Client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return 0, fmt.Errorf("failed to query header %d: %w", number, err)
+		}
+		return header.Time, nil
+	}
+
+	// the history of the chain is produced at its registry block time, regardless of the block time the fork is mined at
+	if l2Cfg.BlockTime > 0 {
+		estimate := low + (l1Header.Time-l2Cfg.Genesis.L2Time)/l2Cfg.BlockTime
+		if estimate < high {
+			estimateTime, err := headerTime(estimate)
+			if err != nil {
+				return 0, err
+			}
+			if estimateTime <= l1Header.Time {
+				nextTime, err := headerTime(estimate + 1)
+				if err != nil {
+					return 0, err
+				}
+				if nextTime > l1Header.Time {
+					return estimate, nil
+				}
+				low = estimate + 1
+			} else {
+				high = estimate
+			}
+		}
+		log.Debug("l2 block time is not constant, searching for the fork height", "chain", l2Cfg.Chain)
+	}
+
+	return searchHeightByTime(ctx, l2Client, low, high, l1Header.Time)
+}
 
-	// We can compute the number of blocks to wind back as the as the block time is fixed.
-	blockNum := latestHeader.Number.Uint64()
-	if latestHeader.Time > l1Header.Time {
-		timeDiff := latestHeader.Time - l1Header.Time
-		blocksToWalkBack := timeDiff / blockTime
-		if timeDiff%2 != 0 {
-			blocksToWalkBack++
+// searchHeightByTime returns the latest block within [low, high) with a timestamp not exceeding the
+// timestamp. The block at low must not exceed the timestamp, while the block at high must
+func searchHeightByTime(ctx context.Context, client *ethclient.Client, low, high, timestamp uint64) (uint64, error) {
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, fmt.Errorf("failed to query header %d: %w", mid, err)
+		}
+		if header.Time <= timestamp {
+			low = mid
+		} else {
+			high = mid
 		}
-		blockNum = blockNum - blocksToWalkBack
 	}
+	return low, nil
+}
 
-	return blockNum, nil
+// l1ForkHeader returns the L1 block to fork at: the latest block at the fork timestamp, the block
+// at the fork height, or otherwise the latest block
+func l1ForkHeader(ctx context.Context, l1Client *ethclient.Client, forkConfig *config.ForkCLIConfig) (*types.Header, error) {
+	var l1ForkHeight *big.Int
+	if forkConfig.L1ForkHeight > 0 {
+		l1ForkHeight = new(big.Int).SetUint64(forkConfig.L1ForkHeight)
+	}
+	header, err := l1Client.HeaderByNumber(ctx, l1ForkHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve L1 header: %w", err)
+	}
+	if forkConfig.Timestamp == 0 {
+		return header, nil
+	}
+
+	if forkConfig.Timestamp > header.Time {
+		return nil, fmt.Errorf("fork timestamp %d is past the latest l1 block at %d", forkConfig.Timestamp, header.Time)
+	}
+	if forkConfig.Timestamp == header.Time {
+		return header, nil
+	}
+
+	number, err := searchHeightByTime(ctx, l1Client, 0, header.Number.Uint64(), forkConfig.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find l1 block at timestamp %d: %w", forkConfig.Timestamp, err)
+	}
+	header, err = l1Client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve L1 header: %w", err)
+	}
+	if header.Time > forkConfig.Timestamp {
+		return nil, fmt.Errorf("fork timestamp %d precedes the l1 genesis", forkConfig.Timestamp)
+	}
+	return header, nil
+}
+
+// checkL2ForkHeight ensures the L2 block derives from an L1 block no later than the L1 fork block,
+// as the deposits and L1 attributes of later L1 blocks would be missing from the forked L1
+func checkL2ForkHeight(ctx context.Context, l2Cfg *registry.ChainConfig, rpcUrl string, height uint64, l1Header *types.Header) error {
+	l2Client, err := ethclient.Dial(rpcUrl)
+	if err != nil {
+		return fmt.Errorf("failed to dial l2 rpc: %w", err)
+	}
+	defer l2Client.Close()
+
+	l1Block, err := bindings.NewL1BlockInteropCaller(predeploys.L1BlockAddr, l2Client)
+	if err != nil {
+		return fmt.Errorf("failed to bind to L1Block: %w", err)
+	}
+	l1Origin, err := l1Block.Number(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(height)})
+	if err != nil {
+		return fmt.Errorf("failed to read l1 origin of %s at height %d: %w", l2Cfg.Chain, height, err)
+	}
+	if l1Origin > l1Header.Number.Uint64() {
+		return fmt.Errorf("%s height %d derives from l1 block %d, past the l1 fork height %d", l2Cfg.Chain, height, l1Origin, l1Header.Number)
+	}
+	return nil
 }
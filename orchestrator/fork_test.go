@@ -0,0 +1,215 @@
+package orchestrator
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	opgenesis "github.com/ethereum-optimism/optimism/op-chain-ops/genesis"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	registry "github.com/ethereum-optimism/superchain-registry/superchain"
+	"github.com/ethereum-optimism/supersim/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/stretchr/testify/require"
+)
+
+// headersAPI serves the headers of a chain with the given block timestamps
+type headersAPI struct {
+	times []uint64
+}
+
+func (api *headersAPI) GetBlockByNumber(number rpc.BlockNumber, _ bool) (*types.Header, error) {
+	n := uint64(number)
+	if number == rpc.LatestBlockNumber {
+		n = uint64(len(api.times) - 1)
+	}
+	if n >= uint64(len(api.times)) {
+		return nil, nil
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(n), Time: api.times[n], Difficulty: new(big.Int)}, nil
+}
+
+func startHeadersServer(t *testing.T, times []uint64) string {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &headersAPI{times}))
+
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+func TestAlignedL2Height(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelInfo)
+
+	// 2 second blocks from genesis at 1000
+	var times []uint64
+	for i := uint64(0); i < 100; i++ {
+		times = append(times, 1000+2*i)
+	}
+	l2Cfg := &registry.ChainConfig{Chain: "test", BlockTime: 2}
+	l2Cfg.Genesis.L2Time = 1000
+	rpcUrl := startHeadersServer(t, times)
+
+	height, err := alignedL2Height(context.Background(), logger, l2Cfg, rpcUrl, &types.Header{Time: 1051})
+	require.NoError(t, err)
+	require.Equal(t, uint64(25), height)
+
+	// beyond the latest block
+	height, err = alignedL2Height(context.Background(), logger, l2Cfg, rpcUrl, &types.Header{Time: 5000})
+	require.NoError(t, err)
+	require.Equal(t, uint64(99), height)
+
+	_, err = alignedL2Height(context.Background(), logger, l2Cfg, rpcUrl, &types.Header{Time: 999})
+	require.Error(t, err)
+
+	// the block time changes from 2 to 1 second after block 50
+	times = times[:51]
+	for i := uint64(1); i < 50; i++ {
+		times = append(times, 1100+i)
+	}
+	rpcUrl = startHeadersServer(t, times)
+
+	height, err = alignedL2Height(context.Background(), logger, l2Cfg, rpcUrl, &types.Header{Time: 1120})
+	require.NoError(t, err)
+	require.Equal(t, uint64(70), height)
+
+	// 1 second blocks, unlike the default 2 second block time the forked chains are mined at
+	times = nil
+	for i := uint64(0); i < 100; i++ {
+		times = append(times, 1000+i)
+	}
+	l2Cfg = &registry.ChainConfig{Chain: "test", BlockTime: 1}
+	l2Cfg.Genesis.L2Time = 1000
+	rpcUrl = startHeadersServer(t, times)
+
+	height, err = alignedL2Height(context.Background(), logger, l2Cfg, rpcUrl, &types.Header{Time: 1051})
+	require.NoError(t, err)
+	require.Equal(t, uint64(51), height)
+}
+
+func TestL1ForkHeader(t *testing.T) {
+	// 12 second blocks from genesis at 1000
+	var times []uint64
+	for i := uint64(0); i < 100; i++ {
+		times = append(times, 1000+12*i)
+	}
+	l1Client, err := ethclient.Dial(startHeadersServer(t, times))
+	require.NoError(t, err)
+	defer l1Client.Close()
+
+	header, err := l1ForkHeader(context.Background(), l1Client, &config.ForkCLIConfig{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(99), header.Number.Uint64())
+
+	header, err = l1ForkHeader(context.Background(), l1Client, &config.ForkCLIConfig{L1ForkHeight: 10})
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), header.Number.Uint64())
+
+	// the latest block at the timestamp
+	header, err = l1ForkHeader(context.Background(), l1Client, &config.ForkCLIConfig{Timestamp: 1250})
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), header.Number.Uint64())
+
+	header, err = l1ForkHeader(context.Background(), l1Client, &config.ForkCLIConfig{Timestamp: 1000})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), header.Number.Uint64())
+
+	_, err = l1ForkHeader(context.Background(), l1Client, &config.ForkCLIConfig{Timestamp: 999})
+	require.Error(t, err)
+
+	_, err = l1ForkHeader(context.Background(), l1Client, &config.ForkCLIConfig{Timestamp: 5000})
+	require.Error(t, err)
+}
+
+func TestEnableForkedInterop(t *testing.T) {
+	networkConfig := config.NetworkConfig{L2Configs: []config.ChainConfig{
+		{ChainID: 10, ForkConfig: &config.ForkConfig{}, L2Config: &config.L2Config{}},
+		{ChainID: 8453, ForkConfig: &config.ForkConfig{}, L2Config: &config.L2Config{}},
+		{ChainID: 7777777, ForkConfig: &config.ForkConfig{}, L2Config: &config.L2Config{}},
+	}}
+	enableForkedInterop(&networkConfig)
+
+	require.Equal(t, []uint64{8453, 7777777}, networkConfig.L2Configs[0].L2Config.DependencySet)
+	require.Equal(t, []uint64{10, 7777777}, networkConfig.L2Configs[1].L2Config.DependencySet)
+	require.Equal(t, []uint64{10, 8453}, networkConfig.L2Configs[2].L2Config.DependencySet)
+	for _, chainCfg := range networkConfig.L2Configs {
+		require.True(t, chainCfg.ForkConfig.InteropPredeploys)
+	}
+}
+
+// predeployStub records the code and storage set on a forked chain, serving its storage
+type predeployStub struct {
+	client  *ethclient.Client
+	code    map[common.Address][]byte
+	storage map[common.Address]map[common.Hash]common.Hash
+}
+
+func (s *predeployStub) GetStorageAt(addr common.Address, slot common.Hash, _ string) hexutil.Bytes {
+	value := s.storage[addr][slot]
+	return value[:]
+}
+
+func (s *predeployStub) EthClient() *ethclient.Client {
+	return s.client
+}
+
+func (s *predeployStub) AnvilSetCode(_ context.Context, account common.Address, code []byte) error {
+	s.code[account] = code
+	return nil
+}
+
+func (s *predeployStub) AnvilSetStorageAt(_ context.Context, account common.Address, slot, value common.Hash) error {
+	if s.storage[account] == nil {
+		s.storage[account] = make(map[common.Hash]common.Hash)
+	}
+	s.storage[account][slot] = value
+	return nil
+}
+
+func TestWriteInteropPredeploys(t *testing.T) {
+	l1BlockImpl := common.HexToAddress("0xc0d3c0d3c0d3c0d3c0d3c0d3c0d3c0d3c0d30015")
+	stub := &predeployStub{
+		code: make(map[common.Address][]byte),
+		storage: map[common.Address]map[common.Hash]common.Hash{
+			predeploys.L1BlockAddr: {opgenesis.ImplementationSlot: common.BytesToHash(l1BlockImpl.Bytes())},
+		},
+	}
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", stub))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	client, err := ethclient.Dial(httpServer.URL)
+	require.NoError(t, err)
+	defer client.Close()
+	stub.client = client
+
+	inboxImpl := common.HexToAddress("0xc0d3c0d3c0d3c0d3c0d3c0d3c0d3c0d3c0d30022")
+	allocs := types.GenesisAlloc{
+		predeploys.CrossL2InboxAddr: {
+			Code:    []byte{0x01},
+			Storage: map[common.Hash]common.Hash{opgenesis.ImplementationSlot: common.BytesToHash(inboxImpl.Bytes())},
+		},
+		inboxImpl: {Code: []byte{0x02}},
+	}
+	l1BlockCode := []byte{0x03}
+
+	require.NoError(t, writeInteropPredeploys(context.Background(), stub, allocs, l1BlockCode))
+
+	require.Equal(t, []byte{0x01}, stub.code[predeploys.CrossL2InboxAddr])
+	require.Equal(t, []byte{0x02}, stub.code[inboxImpl])
+	require.Equal(t, common.BytesToHash(inboxImpl.Bytes()), stub.storage[predeploys.CrossL2InboxAddr][opgenesis.ImplementationSlot])
+
+	// the L1Block implementation is upgraded in place, leaving the proxy untouched
+	require.Equal(t, l1BlockCode, stub.code[l1BlockImpl])
+	require.NotContains(t, stub.code, predeploys.L1BlockAddr)
+}
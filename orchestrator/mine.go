@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum-optimism/supersim/config"
+)
+
+// Time allowed for the deposits of an L1 block to be relayed to every L2
+const depositsTimeout = 10 * time.Second
+
+var ErrNotLockstep = fmt.Errorf("every chain must be started with mining mode `%s`", config.MiningModeNone)
+
+// Mine produces the given number of blocks on the L1 and every L2 with aligned timestamps, which are
+// spaced by the L1 block time. Deposits derived from each L1 block are relayed prior to mining the L2 blocks
+func (o *Orchestrator) Mine(ctx context.Context, blocks uint64) error {
+	for _, chain := range o.anvils() {
+		if chain.Config().MiningConfig.MiningMode() != config.MiningModeNone {
+			return ErrNotLockstep
+		}
+	}
+
+	o.mineMu.Lock()
+	defer o.mineMu.Unlock()
+
+	interval := uint64(o.l1Anvil.Config().MiningConfig.IntervalBlockTime().Round(time.Second) / time.Second)
+	if interval == 0 {
+		interval = 1 // timestamps are in seconds
+	}
+
+	timestamp, err := o.latestTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	timestamp += o.warp
+
+	for i := uint64(0); i < blocks; i++ {
+		timestamp += interval
+		if err := o.mineBlock(ctx, timestamp); err != nil {
+			return fmt.Errorf("failed to mine block %d of %d: %w", i+1, blocks, err)
+		}
+		o.warp = 0
+	}
+
+	o.log.Debug("mined blocks", "blocks", blocks, "timestamp", timestamp)
+	return nil
+}
+
+func (o *Orchestrator) mineBlock(ctx context.Context, timestamp uint64) error {
+	if err := o.l1Anvil.EvmSetNextBlockTimestamp(ctx, timestamp); err != nil {
+		return fmt.Errorf("failed to set timestamp of chain %s: %w", o.l1Anvil.Name(), err)
+	}
+	if err := o.l1Anvil.EvmMine(ctx); err != nil {
+		return fmt.Errorf("failed to mine chain %s: %w", o.l1Anvil.Name(), err)
+	}
+
+	if err := o.waitForDeposits(ctx); err != nil {
+		return err
+	}
+
+	for _, chain := range o.l2Anvils {
+		if err := chain.EvmSetNextBlockTimestamp(ctx, timestamp); err != nil {
+			return fmt.Errorf("failed to set timestamp of chain %s: %w", chain.Name(), err)
+		}
+		if err := chain.EvmMine(ctx); err != nil {
+			return fmt.Errorf("failed to mine chain %s: %w", chain.Name(), err)
+		}
+	}
+	return nil
+}
+
+// waitForDeposits waits until the deposits derived from the L1 head have been submitted to every L2
+func (o *Orchestrator) waitForDeposits(ctx context.Context) error {
+	l1Head, err := o.l1Anvil.EthClient().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l1 head: %w", err)
+	}
+
+	depositsCtx, cancel := context.WithTimeout(ctx, depositsTimeout)
+	defer cancel()
+	for _, opSim := range o.L2OpSims {
+		if err := opSim.WaitForDeposits(depositsCtx, l1Head); err != nil {
+			return fmt.Errorf("failed to relay deposits to chain %s: %w", opSim.Name(), err)
+		}
+	}
+	return nil
+}
+
+// latestTimestamp returns the latest block timestamp across every chain
+func (o *Orchestrator) latestTimestamp(ctx context.Context) (uint64, error) {
+	var timestamp uint64
+	for _, chain := range o.anvils() {
+		header, err := chain.EthClient().HeaderByNumber(ctx, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch head of chain %s: %w", chain.Name(), err)
+		}
+		timestamp = max(timestamp, header.Time)
+	}
+	return timestamp, nil
+}
@@ -22,6 +22,14 @@ type Orchestrator struct {
 
 	l2Anvils map[uint64]*anvil.Anvil
 	L2OpSims map[uint64]*opsimulator.OpSimulator
+
+	snapshots  snapshots
+	supervisor supervisor
+
+	// serializes lockstep block production and time warps
+	mineMu sync.Mutex
+	// seconds warped since the last lockstep block
+	warp uint64
 }
 
 func NewOrchestrator(log log.Logger, networkConfig *config.NetworkConfig) (*Orchestrator, error) {
@@ -29,22 +37,23 @@ func NewOrchestrator(log log.Logger, networkConfig *config.NetworkConfig) (*Orch
 	l1Anvil := anvil.New(log, &networkConfig.L1Config)
 
 	// Spin up L2 anvil instances fronted by opsim
-	nextL2Port := networkConfig.L2StartingPort
+	l2Ports := networkConfig.L2Ports()
 	l2Anvils, L2OpSims := make(map[uint64]*anvil.Anvil), make(map[uint64]*opsimulator.OpSimulator)
 	for i := range networkConfig.L2Configs {
 		cfg := networkConfig.L2Configs[i]
 
 		l2Anvil := anvil.New(log, &cfg)
 		l2Anvils[cfg.ChainID] = l2Anvil
-		L2OpSims[cfg.ChainID] = opsimulator.New(log, nextL2Port, l1Anvil, l2Anvil, cfg.L2Config, l2Anvils)
-
-		// only increment expected port if it has been specified
-		if nextL2Port > 0 {
-			nextL2Port++
-		}
+		L2OpSims[cfg.ChainID] = opsimulator.New(log, l2Ports[i], l1Anvil, l2Anvil, cfg.L2Config, networkConfig, l2Anvils)
 	}
 
-	return &Orchestrator{log, l1Anvil, l2Anvils, L2OpSims}, nil
+	o := &Orchestrator{log: log, l1Anvil: l1Anvil, l2Anvils: l2Anvils, L2OpSims: L2OpSims}
+	for _, opSim := range L2OpSims {
+		opSim.SetWarp(o.warpAndMine)
+	}
+	o.supervisor.cfg = networkConfig.SupervisorConfig
+	o.supervisor.errCh = make(chan error, len(l2Anvils)+1)
+	return o, nil
 }
 
 func (o *Orchestrator) Start(ctx context.Context) error {
@@ -57,6 +66,9 @@ func (o *Orchestrator) Start(ctx context.Context) error {
 		if err := anvil.Start(ctx); err != nil {
 			return fmt.Errorf("anvil instance %s failed to start: %w", anvil.Name(), err)
 		}
+		if err := o.prepareL2Anvil(ctx, anvil); err != nil {
+			return err
+		}
 	}
 	for _, opSim := range o.L2OpSims {
 		if err := opSim.Start(ctx); err != nil {
@@ -68,12 +80,27 @@ func (o *Orchestrator) Start(ctx context.Context) error {
 		return fmt.Errorf("orchestrator failed to get ready: %w", err)
 	}
 
+	o.startSupervisor(ctx)
+
 	o.log.Debug("orchestrator is ready")
 	return nil
 }
 
+// prepareL2Anvil applies the state the chain relies on that is not part of its genesis or fork
+func (o *Orchestrator) prepareL2Anvil(ctx context.Context, chain *anvil.Anvil) error {
+	if forkCfg := chain.Config().ForkConfig; forkCfg != nil && forkCfg.InteropPredeploys {
+		if err := setInteropPredeploys(ctx, chain); err != nil {
+			return fmt.Errorf("failed to set interop predeploys on %s: %w", chain.Name(), err)
+		}
+		o.log.Debug("set interop predeploys", "name", chain.Name(), "chain.id", chain.ChainID())
+	}
+	return nil
+}
+
 func (o *Orchestrator) Stop(ctx context.Context) error {
 	o.log.Info("stopping orchestrator")
+	o.stopSupervisor()
+
 	for _, opSim := range o.L2OpSims {
 		if err := opSim.Stop(ctx); err != nil {
 			return fmt.Errorf("op simulator chain.id=%d failed to stop: %w", opSim.ChainID(), err)
@@ -95,18 +122,16 @@ func (o *Orchestrator) Stop(ctx context.Context) error {
 	return nil
 }
 
+// Stopped reports if every component has stopped. Crashed anvil instances count as stopped
 func (o *Orchestrator) Stopped() bool {
-	if stopped := o.l1Anvil.Stopped(); stopped {
-		return stopped
-	}
-	for _, anvil := range o.l2Anvils {
-		if stopped := anvil.Stopped(); !stopped {
-			return stopped
+	for _, anvil := range o.anvils() {
+		if !anvil.Stopped() {
+			return false
 		}
 	}
 	for _, opSim := range o.L2OpSims {
-		if stopped := opSim.Stopped(); !stopped {
-			return stopped
+		if !opSim.Stopped() {
+			return false
 		}
 	}
 	return true
@@ -156,6 +181,14 @@ func (o *Orchestrator) L2Chains() []config.Chain {
 	return chains
 }
 
+func (o *Orchestrator) L2Chain(chainID uint64) config.Chain {
+	chain, ok := o.l2Anvils[chainID]
+	if !ok {
+		return nil
+	}
+	return chain
+}
+
 func (o *Orchestrator) ConfigAsString() string {
 	var b strings.Builder
 
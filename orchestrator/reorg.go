@@ -0,0 +1,51 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/supersim/anvil"
+)
+
+// ReorgL1 replaces the latest blocks of the L1 with as many new blocks. The transactions of each replaced block
+// are re-included a block later, or in the last new block, such that deposits are re-derived with new source hashes.
+// Each L2 rolls back the blocks including deposits of the replaced blocks, along with every transaction since, and
+// relays the new blocks. Returns once the new L1 head has been relayed to every L2
+func (o *Orchestrator) ReorgL1(ctx context.Context, depth uint64) error {
+	if depth == 0 {
+		return fmt.Errorf("reorg depth must be positive")
+	}
+
+	o.mineMu.Lock()
+	defer o.mineMu.Unlock()
+
+	head, err := o.l1Anvil.EthClient().BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l1 head: %w", err)
+	}
+	if depth > head {
+		return fmt.Errorf("reorg depth %d exceeds the l1 height %d", depth, head)
+	}
+
+	var txs []anvil.ReorgTx
+	for i := uint64(0); i < depth; i++ {
+		block, err := o.l1Anvil.EthBlockByNumber(ctx, new(big.Int).SetUint64(head-depth+1+i))
+		if err != nil {
+			return fmt.Errorf("failed to fetch l1 block: %w", err)
+		}
+		for _, tx := range block.Transactions() {
+			txs = append(txs, anvil.ReorgTx{Tx: tx, Block: min(i+1, depth-1)})
+		}
+	}
+
+	if err := o.l1Anvil.AnvilReorg(ctx, depth, txs); err != nil {
+		return fmt.Errorf("failed to reorg chain %s: %w", o.l1Anvil.Name(), err)
+	}
+	if err := o.waitForDeposits(ctx); err != nil {
+		return err
+	}
+
+	o.log.Debug("reorged l1", "depth", depth)
+	return nil
+}
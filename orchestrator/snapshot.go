@@ -0,0 +1,162 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum-optimism/supersim/anvil"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+
+	// A revert failed after reverting some of the chains, leaving the network inconsistent
+	ErrSnapshotsBroken = errors.New("snapshot set is broken")
+)
+
+// snapshot holds the anvil snapshot of every chain, keyed by chain id
+type snapshot map[uint64]chainSnapshot
+
+type chainSnapshot struct {
+	id *big.Int
+
+	// anvil snapshots are lost when the process restarts
+	starts uint64
+}
+
+type snapshots struct {
+	mu     sync.Mutex
+	nextID uint64
+	byID   map[uint64]snapshot
+}
+
+// Snapshot captures the state of the L1 and every L2 together. Deposit relaying is paused
+// so that no deposit lands on an L2 without the L1 state it was derived from
+func (o *Orchestrator) Snapshot(ctx context.Context) (uint64, error) {
+	o.snapshots.mu.Lock()
+	defer o.snapshots.mu.Unlock()
+
+	o.pauseDeposits()
+	defer o.resumeDeposits()
+
+	snap := make(snapshot)
+	for _, chain := range o.anvils() {
+		id, err := chain.EvmSnapshot(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to snapshot chain %s: %w", chain.Name(), err)
+		}
+		snap[chain.ChainID()] = chainSnapshot{id: id, starts: chain.Starts()}
+	}
+
+	if o.snapshots.byID == nil {
+		o.snapshots.byID = make(map[uint64]snapshot)
+	}
+
+	id := o.snapshots.nextID
+	o.snapshots.byID[id] = snap
+	o.snapshots.nextID++
+
+	o.log.Debug("took snapshot", "id", id)
+	return id, nil
+}
+
+// Revert restores every chain to the given snapshot. Like anvil, the snapshot and any taken after it are consumed
+// by the revert. The snapshot of every chain is checked to exist prior to reverting any chain. Should a chain
+// nonetheless fail to revert, every snapshot is discarded and ErrSnapshotsBroken returned
+func (o *Orchestrator) Revert(ctx context.Context, id uint64) error {
+	o.snapshots.mu.Lock()
+	defer o.snapshots.mu.Unlock()
+
+	snap, ok := o.snapshots.byID[id]
+	if !ok {
+		return ErrSnapshotNotFound
+	}
+
+	chains := o.anvils()
+	for _, chain := range chains {
+		chainSnap, ok := snap[chain.ChainID()]
+		if !ok {
+			return fmt.Errorf("snapshot %d is missing chain %s", id, chain.Name())
+		}
+		if chainSnap.starts != chain.Starts() {
+			o.discardStaleSnapshots()
+			return fmt.Errorf("%w: chain %s restarted since snapshot %d was taken", ErrSnapshotNotFound, chain.Name(), id)
+		}
+	}
+
+	o.pauseDeposits()
+	defer o.resumeDeposits()
+
+	for i, chain := range chains {
+		reverted, err := chain.EvmRevert(ctx, snap[chain.ChainID()].id)
+		if err == nil && !reverted {
+			err = errors.New("revert rejected")
+		}
+		if err != nil {
+			if i == 0 {
+				return fmt.Errorf("failed to revert chain %s: %w", chain.Name(), err)
+			}
+
+			o.snapshots.byID = nil
+			return fmt.Errorf("%w: chain %s failed to revert to snapshot %d after %d chains were reverted, every snapshot was discarded: %w",
+				ErrSnapshotsBroken, chain.Name(), id, i, err)
+		}
+	}
+
+	l1Head, err := o.l1Anvil.EthClient().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l1 head: %w", err)
+	}
+	for _, opSim := range o.L2OpSims {
+		opSim.RewindDepositRelay(l1Head.Number.Uint64())
+	}
+
+	for snapID := range o.snapshots.byID {
+		if snapID >= id {
+			delete(o.snapshots.byID, snapID)
+		}
+	}
+
+	o.log.Debug("reverted to snapshot", "id", id)
+	return nil
+}
+
+// discardStaleSnapshots removes the snapshots of chains restarted since they were taken
+func (o *Orchestrator) discardStaleSnapshots() {
+	starts := make(map[uint64]uint64)
+	for _, chain := range o.anvils() {
+		starts[chain.ChainID()] = chain.Starts()
+	}
+	for snapID, snap := range o.snapshots.byID {
+		for chainID, chainSnap := range snap {
+			if chainSnap.starts != starts[chainID] {
+				delete(o.snapshots.byID, snapID)
+				break
+			}
+		}
+	}
+}
+
+// The L1 is first so that it is snapshotted, and reverted, prior to the L2s deriving from it
+func (o *Orchestrator) anvils() []*anvil.Anvil {
+	chains := []*anvil.Anvil{o.l1Anvil}
+	for _, chain := range o.l2Anvils {
+		chains = append(chains, chain)
+	}
+	return chains
+}
+
+func (o *Orchestrator) pauseDeposits() {
+	for _, opSim := range o.L2OpSims {
+		opSim.PauseDeposits()
+	}
+}
+
+func (o *Orchestrator) resumeDeposits() {
+	for _, opSim := range o.L2OpSims {
+		opSim.ResumeDeposits()
+	}
+}
@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/supersim/anvil"
+	"github.com/ethereum-optimism/supersim/config"
+)
+
+type supervisor struct {
+	cfg config.SupervisorConfig
+
+	// serializes restarts, as every op-simulator is restarted alongside a chain
+	restartMu sync.Mutex
+
+	errCh  chan error
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// Err receives an error when a chain terminates unexpectedly and is not restarted
+func (o *Orchestrator) Err() <-chan error {
+	return o.supervisor.errCh
+}
+
+func (o *Orchestrator) startSupervisor(ctx context.Context) {
+	ctx, o.supervisor.cancel = context.WithCancel(ctx)
+
+	for _, chain := range o.anvils() {
+		o.supervisor.wg.Add(1)
+		go func() {
+			defer o.supervisor.wg.Done()
+			o.superviseAnvil(ctx, chain)
+		}()
+	}
+
+	if interval := o.supervisor.cfg.StateDumpInterval; interval > 0 {
+		o.supervisor.wg.Add(1)
+		go func() {
+			defer o.supervisor.wg.Done()
+			o.dumpStatePeriodically(ctx, time.Duration(interval)*time.Second)
+		}()
+	}
+}
+
+func (o *Orchestrator) stopSupervisor() {
+	if o.supervisor.cancel != nil {
+		o.supervisor.cancel()
+	}
+	o.supervisor.wg.Wait()
+}
+
+func (o *Orchestrator) superviseAnvil(ctx context.Context, chain *anvil.Anvil) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-chain.Exited():
+		}
+
+		// anvil is also terminated when the context is cancelled
+		if ctx.Err() != nil || !chain.Crashed() {
+			return
+		}
+
+		exitErr := chain.ExitErr()
+		if exitErr == nil {
+			exitErr = errors.New("exited without an error")
+		}
+
+		err := fmt.Errorf("anvil instance %s terminated unexpectedly: %w", chain.Name(), exitErr)
+		o.log.Error("anvil instance crashed", "chain.id", chain.ChainID(), "err", exitErr, "log.path", chain.LogPath())
+
+		if !o.supervisor.cfg.RestartOnCrash {
+			o.reportErr(err)
+			return
+		}
+
+		if err := o.restartAnvil(ctx, chain); err != nil {
+			o.reportErr(fmt.Errorf("failed to restart anvil instance %s: %w", chain.Name(), err))
+			return
+		}
+	}
+}
+
+func (o *Orchestrator) restartAnvil(ctx context.Context, chain *anvil.Anvil) error {
+	o.supervisor.restartMu.Lock()
+	defer o.supervisor.restartMu.Unlock()
+
+	o.log.Warn("restarting anvil instance", "name", chain.Name(), "chain.id", chain.ChainID())
+	if err := chain.Restart(ctx); err != nil {
+		return err
+	}
+	if err := chain.WaitUntilReady(ctx); err != nil {
+		return err
+	}
+	if chain != o.l1Anvil {
+		if err := o.prepareL2Anvil(ctx, chain); err != nil {
+			return err
+		}
+	}
+
+	// Any op-simulator may hold subscriptions against the restarted chain
+	for _, opSim := range o.L2OpSims {
+		if err := opSim.RestartTasks(); err != nil {
+			return fmt.Errorf("failed to restart op simulator %s: %w", opSim.Name(), err)
+		}
+	}
+
+	o.log.Info("restarted anvil instance", "name", chain.Name(), "chain.id", chain.ChainID(), "rpc", chain.Endpoint())
+	return nil
+}
+
+func (o *Orchestrator) dumpStatePeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, chain := range o.anvils() {
+				if chain.Config().StatePath == "" || chain.Stopped() {
+					continue
+				}
+				if err := chain.DumpState(ctx); err != nil {
+					o.log.Error("failed to dump chain state", "chain.id", chain.ChainID(), "err", err)
+				}
+			}
+		}
+	}
+}
+
+// The channel is buffered for every chain, so reporting never blocks
+func (o *Orchestrator) reportErr(err error) {
+	select {
+	case o.supervisor.errCh <- err:
+	default:
+	}
+}
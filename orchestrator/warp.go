@@ -0,0 +1,40 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Warp advances the clock of the L1 and every L2 by the same number of seconds. No blocks are
+// mined, the warp is observed by the next block of each chain. In lockstep, the next blocks
+// mined with Mine are offset by the warp
+func (o *Orchestrator) Warp(ctx context.Context, seconds uint64) error {
+	o.mineMu.Lock()
+	defer o.mineMu.Unlock()
+
+	for _, chain := range o.anvils() {
+		if err := chain.EvmIncreaseTime(ctx, seconds); err != nil {
+			return fmt.Errorf("failed to warp chain %s: %w", chain.Name(), err)
+		}
+	}
+
+	o.warp += seconds
+	o.log.Debug("warped time", "seconds", seconds)
+	return nil
+}
+
+// warpAndMine warps every chain and mines a block observing the warp. In lockstep every chain is mined,
+// otherwise only the L1, as the L2s observe the warp with their next block
+func (o *Orchestrator) warpAndMine(ctx context.Context, seconds uint64) error {
+	if err := o.Warp(ctx, seconds); err != nil {
+		return err
+	}
+	if err := o.Mine(ctx, 1); !errors.Is(err, ErrNotLockstep) {
+		return err
+	}
+	if err := o.l1Anvil.EvmMine(ctx); err != nil {
+		return fmt.Errorf("failed to mine chain %s: %w", o.l1Anvil.Name(), err)
+	}
+	return nil
+}
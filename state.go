@@ -0,0 +1,150 @@
+package supersim
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ethereum-optimism/supersim/config"
+	"github.com/ethereum-optimism/supersim/orchestrator"
+)
+
+const stateMetadataFileName = "supersim.json"
+
+// stateMetadata records the topology a state directory was dumped from, so that a
+// following session restores the chain states onto the same chains and endpoints
+type stateMetadata struct {
+	L1  chainStateMetadata   `json:"l1"`
+	L2s []chainStateMetadata `json:"l2s"`
+}
+
+type chainStateMetadata struct {
+	Name    string `json:"name"`
+	ChainID uint64 `json:"chainId"`
+	Port    uint64 `json:"port"`
+
+	DependencySet []uint64             `json:"dependencySet,omitempty"`
+	RelayCursors  *config.RelayCursors `json:"relayCursors,omitempty"`
+	ForkConfig    *config.ForkConfig   `json:"forkConfig,omitempty"`
+
+	StateFile string `json:"stateFile"`
+}
+
+func chainStatePath(stateDir string, chainID uint64) string {
+	return filepath.Join(stateDir, fmt.Sprintf("chain-%d.state", chainID))
+}
+
+// applyStateDir points every chain at its state file within the directory. The metadata of
+// a previous session is validated against the network and its ports are reused for any chain
+// that would otherwise bind to an available port
+func applyStateDir(stateDir string, networkConfig *config.NetworkConfig) error {
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// the chain configs may be shared with the defaults, so they are copied prior to being modified
+	networkConfig.L2Configs = slices.Clone(networkConfig.L2Configs)
+
+	networkConfig.L1Config.StatePath = chainStatePath(stateDir, networkConfig.L1Config.ChainID)
+	for i := range networkConfig.L2Configs {
+		cfg := &networkConfig.L2Configs[i]
+		cfg.StatePath = chainStatePath(stateDir, cfg.ChainID)
+		if cfg.L2Config != nil {
+			l2Config := *cfg.L2Config
+			cfg.L2Config = &l2Config
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(stateDir, stateMetadataFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read state metadata: %w", err)
+	}
+
+	var metadata stateMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return fmt.Errorf("failed to parse state metadata: %w", err)
+	}
+
+	if err := metadata.L1.restore(&networkConfig.L1Config, networkConfig.L1Config.Port == 0); err != nil {
+		return err
+	}
+	for _, l2 := range metadata.L2s {
+		idx := slices.IndexFunc(networkConfig.L2Configs, func(cfg config.ChainConfig) bool { return cfg.ChainID == l2.ChainID })
+		if idx < 0 {
+			return fmt.Errorf("state directory contains chain %s (%d) which is not configured", l2.Name, l2.ChainID)
+		}
+		if err := l2.restore(&networkConfig.L2Configs[idx], networkConfig.L2StartingPort == 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *chainStateMetadata) restore(cfg *config.ChainConfig, reusePort bool) error {
+	if m.ChainID != cfg.ChainID {
+		return fmt.Errorf("state directory was dumped from chain id %d, not %d", m.ChainID, cfg.ChainID)
+	}
+	if (m.ForkConfig == nil) != (cfg.ForkConfig == nil) {
+		return fmt.Errorf("state of chain %d cannot be restored when switching between fork and vanilla mode", cfg.ChainID)
+	}
+
+	if cfg.L2Config != nil {
+		// the dependency set may have been changed at runtime through the admin server
+		if m.DependencySet != nil {
+			cfg.L2Config.DependencySet = slices.Clone(m.DependencySet)
+		}
+		cfg.L2Config.RelayCursors = m.RelayCursors
+	}
+
+	if !reusePort {
+		return nil
+	}
+
+	// The L2 anvil instances are always bound to an available port behind the op-simulator
+	if cfg.L2Config != nil {
+		if cfg.L2Config.Port == 0 {
+			cfg.L2Config.Port = m.Port
+		}
+	} else {
+		cfg.Port = m.Port
+	}
+	return nil
+}
+
+// writeStateMetadata records the topology of the running orchestrator next to the dumped chain states
+func writeStateMetadata(stateDir string, o *orchestrator.Orchestrator) error {
+	l1 := o.L1Chain()
+	metadata := stateMetadata{L1: newChainStateMetadata(l1.Config(), l1.Config().Port, nil, nil)}
+	for _, opSim := range o.L2OpSims {
+		metadata.L2s = append(metadata.L2s, newChainStateMetadata(opSim.Config(), opSim.Port(), opSim.DependencySet(), opSim.RelayCursors()))
+	}
+	slices.SortFunc(metadata.L2s, func(a, b chainStateMetadata) int { return cmp.Compare(a.ChainID, b.ChainID) })
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(stateDir, stateMetadataFileName), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write state metadata: %w", err)
+	}
+	return nil
+}
+
+func newChainStateMetadata(cfg *config.ChainConfig, port uint64, depSet []uint64, relayCursors *config.RelayCursors) chainStateMetadata {
+	return chainStateMetadata{
+		Name:          cfg.Name,
+		ChainID:       cfg.ChainID,
+		Port:          port,
+		DependencySet: depSet,
+		RelayCursors:  relayCursors,
+		ForkConfig:    cfg.ForkConfig,
+		StateFile:     filepath.Base(cfg.StatePath),
+	}
+}
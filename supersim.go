@@ -3,11 +3,14 @@ package supersim
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
-	registry "github.com/ethereum-optimism/superchain-registry/superchain"
+	"github.com/ethereum-optimism/supersim/admin"
 	"github.com/ethereum-optimism/supersim/config"
+	"github.com/ethereum-optimism/supersim/forkcache"
 	"github.com/ethereum-optimism/supersim/orchestrator"
+	"github.com/ethereum-optimism/supersim/supervisor"
 
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -15,17 +18,52 @@ import (
 type Supersim struct {
 	log          log.Logger
 	Orchestrator *orchestrator.Orchestrator
+	AdminServer  *admin.AdminServer
+
+	// op-supervisor stand-in for validating interop messages
+	SupervisorServer *supervisor.SupervisorServer
+
+	stateDir string
+
+	// Proxies the forked networks when forking with a cache
+	forkCache *forkcache.Cache
+
+	// Prefunded accounts of every chain, noting those supersim submits transactions from
+	secrets string
 }
 
 func NewSupersim(log log.Logger, envPrefix string, cliConfig *config.CLIConfig) (*Supersim, error) {
 	networkConfig := config.DefaultNetworkConfig
-	if cliConfig.ForkConfig != nil {
-		superchain := registry.Superchains[cliConfig.ForkConfig.Network]
-		log.Info("generating fork configuration", "superchain", superchain.Superchain)
-
+	var forkCache *forkcache.Cache
+	if cliConfig.ConfigPath != "" {
 		var err error
-		networkConfig, err = orchestrator.NetworkConfigFromForkCLIConfig(log, envPrefix, cliConfig.ForkConfig)
+		networkConfig, err = config.ReadNetworkConfigFile(cliConfig.ConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read network configuration: %w", err)
+		}
+
+		log.Info("loaded network configuration", "path", cliConfig.ConfigPath, "l2.chains", len(networkConfig.L2Configs))
+	} else if cliConfig.ForkConfig != nil {
+		log.Info("generating fork configuration", "superchain", cliConfig.ForkConfig.Network)
+
+		l2MiningConfig, err := cliConfig.L2MiningConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		if cliConfig.ForkConfig.CacheDir != "" {
+			forkCache, err = forkcache.NewCache(log, cliConfig.ForkConfig.CacheDir, cliConfig.ForkConfig.Offline)
+			if err != nil {
+				return nil, err
+			}
+			log.Info("using fork cache", "dir", cliConfig.ForkConfig.CacheDir, "offline", cliConfig.ForkConfig.Offline)
+		}
+
+		networkConfig, err = orchestrator.NetworkConfigFromForkCLIConfig(log, envPrefix, cliConfig.ForkConfig, l2MiningConfig, forkCache)
 		if err != nil {
+			if forkCache != nil {
+				_ = forkCache.Stop(context.Background())
+			}
 			return nil, fmt.Errorf("failed to construct fork configuration: %w", err)
 		}
 
@@ -33,23 +71,85 @@ func NewSupersim(log log.Logger, envPrefix string, cliConfig *config.CLIConfig)
 		if cliConfig.ForkConfig.L1ForkHeight > 0 {
 			l1ForkHeightStr = fmt.Sprintf("%d", cliConfig.ForkConfig.L1ForkHeight)
 		}
-		log.Info("forked l1 chain config", "name", superchain.Superchain, "chain.id", networkConfig.L1Config.ChainID, "fork.height", l1ForkHeightStr)
+		log.Info("forked l1 chain config", "name", cliConfig.ForkConfig.Network, "chain.id", networkConfig.L1Config.ChainID, "fork.height", l1ForkHeightStr)
 		for _, chainCfg := range networkConfig.L2Configs {
-			name := registry.OPChains[chainCfg.ChainID].Chain
-			log.Info("forked l2 chain config", "name", name, "chain.id", chainCfg.ChainID, "fork.height", chainCfg.ForkConfig.BlockNumber)
+			log.Info("forked l2 chain config", "name", chainCfg.Name, "chain.id", chainCfg.ChainID, "fork.height", chainCfg.ForkConfig.BlockNumber)
+		}
+	}
+
+	// Forward set ports and block production. Setting `0` will work to allocate a random
+	// port. Values declared in a configuration file take precedence
+	if cliConfig.ConfigPath == "" {
+		networkConfig.L1Config.Port = cliConfig.L1Port
+		networkConfig.L2StartingPort = cliConfig.L2StartingPort
+
+		if err := applyCLIMiningConfig(cliConfig, &networkConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	networkConfig.InteropConfig.AutoRelay = cliConfig.InteropAutoRelay
+	networkConfig.InteropConfig.RelayerAccountIndex = uint32(cliConfig.InteropRelayerAccount)
+	if networkConfig.InteropConfig.MessageExpiryWindow == 0 {
+		// a window declared in the configuration file takes precedence
+		networkConfig.InteropConfig.MessageExpiryWindow = cliConfig.InteropExpiryWindow
+	}
+
+	networkConfig.WithdrawalConfig.AutoFinalize = cliConfig.WithdrawalsAutoFinalize
+	networkConfig.WithdrawalConfig.FinalizationDelay = cliConfig.WithdrawalsDelay
+	networkConfig.WithdrawalConfig.AccountIndex = uint32(cliConfig.WithdrawalsAccount)
+
+	networkConfig.SafetyConfig.FollowL1 = cliConfig.L2SafetyFollowL1
+	networkConfig.SafetyConfig.SafeLag = cliConfig.L2SafeLag
+	networkConfig.SafetyConfig.FinalizedLag = cliConfig.L2FinalizedLag
+
+	networkConfig.SupervisorConfig.RestartOnCrash = cliConfig.AnvilRestart
+	networkConfig.SupervisorConfig.StateDumpInterval = cliConfig.StateInterval
+
+	if cliConfig.StateDir != "" {
+		if err := applyStateDir(cliConfig.StateDir, &networkConfig); err != nil {
+			return nil, fmt.Errorf("failed to apply state directory: %w", err)
 		}
 	}
 
-	// Forward set ports. Setting `0` will work to allocate a random port
-	networkConfig.L1Config.Port = cliConfig.L1Port
-	networkConfig.L2StartingPort = cliConfig.L2StartingPort
+	if err := networkConfig.Check(); err != nil {
+		return nil, fmt.Errorf("invalid network configuration: %w", err)
+	}
 
 	o, err := orchestrator.NewOrchestrator(log, &networkConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create orchestrator")
 	}
 
-	return &Supersim{log, o}, nil
+	return &Supersim{
+		log:              log,
+		Orchestrator:     o,
+		AdminServer:      admin.NewAdminServer(log, cliConfig.AdminPort, o),
+		SupervisorServer: supervisor.NewSupervisorServer(log, cliConfig.SupervisorPort, o),
+		stateDir:         cliConfig.StateDir,
+		forkCache:        forkCache,
+		secrets:          networkConfig.SecretsAsString(),
+	}, nil
+}
+
+func applyCLIMiningConfig(cliConfig *config.CLIConfig, networkConfig *config.NetworkConfig) error {
+	l1MiningConfig, err := cliConfig.L1MiningConfig()
+	if err != nil {
+		return fmt.Errorf("invalid l1 mining config: %w", err)
+	}
+	l2MiningConfig, err := cliConfig.L2MiningConfig()
+	if err != nil {
+		return fmt.Errorf("invalid l2 mining config: %w", err)
+	}
+
+	// the chain configs may be shared with the defaults, so they are copied prior to being modified
+	networkConfig.L2Configs = slices.Clone(networkConfig.L2Configs)
+
+	networkConfig.L1Config.MiningConfig = l1MiningConfig
+	for i := range networkConfig.L2Configs {
+		networkConfig.L2Configs[i].MiningConfig = l2MiningConfig
+	}
+	return nil
 }
 
 func (s *Supersim) Start(ctx context.Context) error {
@@ -59,6 +159,13 @@ func (s *Supersim) Start(ctx context.Context) error {
 		return fmt.Errorf("orchestrator failed to start: %w", err)
 	}
 
+	if err := s.AdminServer.Start(ctx); err != nil {
+		return fmt.Errorf("admin server failed to start: %w", err)
+	}
+	if err := s.SupervisorServer.Start(ctx); err != nil {
+		return fmt.Errorf("supervisor server failed to start: %w", err)
+	}
+
 	s.log.Info("supersim is ready")
 	s.log.Info(s.ConfigAsString())
 	return nil
@@ -66,9 +173,28 @@ func (s *Supersim) Start(ctx context.Context) error {
 
 func (s *Supersim) Stop(ctx context.Context) error {
 	s.log.Info("stopping supersim")
+	if err := s.AdminServer.Stop(ctx); err != nil {
+		return fmt.Errorf("admin server failed to stop: %w", err)
+	}
+	if err := s.SupervisorServer.Stop(ctx); err != nil {
+		return fmt.Errorf("supervisor server failed to stop: %w", err)
+	}
 	if err := s.Orchestrator.Stop(ctx); err != nil {
 		return fmt.Errorf("orchestrator failed to stop: %w", err)
 	}
+	if s.forkCache != nil {
+		if err := s.forkCache.Stop(ctx); err != nil {
+			return fmt.Errorf("fork cache failed to stop: %w", err)
+		}
+	}
+
+	// the chain states are dumped by the orchestrator
+	if s.stateDir != "" {
+		if err := writeStateMetadata(s.stateDir, s.Orchestrator); err != nil {
+			return err
+		}
+		s.log.Info("saved state", "dir", s.stateDir)
+	}
 
 	s.log.Info("stopped supersim")
 	return nil
@@ -80,7 +206,10 @@ func (s *Supersim) Stopped() bool {
 
 func (s *Supersim) ConfigAsString() string {
 	var b strings.Builder
-	fmt.Fprint(&b, config.DefaultSecretsConfigAsString())
+	fmt.Fprint(&b, s.secrets)
+
+	fmt.Fprintf(&b, "\nAdmin RPC: %s\n", s.AdminServer.Endpoint())
+	fmt.Fprintf(&b, "Supervisor RPC: %s\n", s.SupervisorServer.Endpoint())
 
 	fmt.Fprintf(&b, "\nOrchestrator Config:\n")
 	fmt.Fprint(&b, s.Orchestrator.ConfigAsString())
@@ -2,6 +2,7 @@ package supersim
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"math/big"
 	"strings"
 	"sync"
@@ -11,11 +12,14 @@ import (
 	opbindings "github.com/ethereum-optimism/optimism/op-e2e/bindings"
 	"github.com/ethereum-optimism/optimism/op-service/predeploys"
 	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	registry "github.com/ethereum-optimism/superchain-registry/superchain"
+	"github.com/ethereum-optimism/supersim/admin"
 	"github.com/ethereum-optimism/supersim/bindings"
 	"github.com/ethereum-optimism/supersim/config"
 	"github.com/ethereum-optimism/supersim/hdaccount"
 	"github.com/ethereum-optimism/supersim/opsimulator"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -28,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -83,6 +88,10 @@ type InteropTestSuite struct {
 
 func createTestSuite(t *testing.T) *TestSuite {
 	cfg := &config.CLIConfig{} // does not run in fork mode
+	return createTestSuiteWithCLIConfig(t, cfg)
+}
+
+func createTestSuiteWithCLIConfig(t *testing.T, cfg *config.CLIConfig) *TestSuite {
 	testlog := testlog.Logger(t, log.LevelInfo)
 	supersim, _ := NewSupersim(testlog, "", cfg)
 
@@ -125,8 +134,8 @@ func createInteropTestSuite(t *testing.T) *InteropTestSuite {
 	destChainID := new(big.Int).SetUint64(config.DefaultNetworkConfig.L2Configs[1].ChainID)
 	sourceChainID := new(big.Int).SetUint64(config.DefaultNetworkConfig.L2Configs[0].ChainID)
 
-	// TODO: fix when we add a wait for ready on the opsim
-	time.Sleep(3 * time.Second)
+	waitForDependencySet(t, sourceOpSim)
+	waitForDependencySet(t, destOpSim)
 
 	return &InteropTestSuite{
 		t:               t,
@@ -223,6 +232,285 @@ func TestAccountBalances(t *testing.T) {
 	}
 }
 
+func TestMiningModes(t *testing.T) {
+	t.Run("interval", func(t *testing.T) {
+		testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{L1BlockTime: 500 * time.Millisecond})
+		l1Client := testSuite.Supersim.Orchestrator.L1Chain().EthClient()
+
+		start, err := l1Client.BlockNumber(context.Background())
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			number, err := l1Client.BlockNumber(context.Background())
+			return err == nil && number >= start+2
+		}, 3*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{MiningMode: string(config.MiningModeAuto)})
+		l1Client := testSuite.Supersim.Orchestrator.L1Chain().EthClient()
+
+		privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+		require.NoError(t, err)
+		nonce, err := l1Client.PendingNonceAt(context.Background(), crypto.PubkeyToAddress(privateKey.PublicKey))
+		require.NoError(t, err)
+		start, err := l1Client.BlockNumber(context.Background())
+		require.NoError(t, err)
+
+		chainID := new(big.Int).SetUint64(testSuite.Supersim.Orchestrator.L1Chain().ChainID())
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(1), 21000, big.NewInt(10000000000), nil), types.NewEIP155Signer(chainID), privateKey)
+		require.NoError(t, err)
+		require.NoError(t, l1Client.SendTransaction(context.Background(), tx))
+
+		// the transaction is mined immediately in its own block
+		receipt, err := l1Client.TransactionReceipt(context.Background(), tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, start+1, receipt.BlockNumber.Uint64())
+	})
+
+	t.Run("none", func(t *testing.T) {
+		testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{MiningMode: string(config.MiningModeNone)})
+		l1Client := testSuite.Supersim.Orchestrator.L1Chain().EthClient()
+
+		start, err := l1Client.BlockNumber(context.Background())
+		require.NoError(t, err)
+		time.Sleep(3 * time.Second)
+		number, err := l1Client.BlockNumber(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, start, number)
+	})
+}
+
+func TestLockstepMining(t *testing.T) {
+	testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{MiningMode: string(config.MiningModeNone)})
+
+	adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+	require.NoError(t, err)
+	defer adminClient.Close()
+
+	l1Chain := testSuite.Supersim.Orchestrator.L1Chain()
+	l2Chain := testSuite.Supersim.Orchestrator.L2Chains()[0]
+
+	// deposit on the L1, included once mined
+	privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+	require.NoError(t, err)
+	senderAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+	prevBalance, err := l2Chain.EthClient().BalanceAt(context.Background(), senderAddress, nil)
+	require.NoError(t, err)
+
+	oneEth := big.NewInt(1e18)
+	transactor, err := bind.NewKeyedTransactorWithChainID(privateKey, new(big.Int).SetUint64(l1Chain.ChainID()))
+	require.NoError(t, err)
+	transactor.Value = oneEth
+	transactor.GasLimit = 500000
+	optimismPortal, err := opbindings.NewOptimismPortal(common.Address(l2Chain.Config().L2Config.L1Addresses.OptimismPortalProxy), l1Chain.EthClient())
+	require.NoError(t, err)
+	_, err = optimismPortal.DepositTransaction(transactor, senderAddress, oneEth, 100000, false, make([]byte, 0))
+	require.NoError(t, err)
+
+	chains := append([]config.Chain{l1Chain}, testSuite.Supersim.Orchestrator.L2Chains()...)
+	startNumbers := make([]uint64, len(chains))
+	for i, chain := range chains {
+		startNumbers[i], err = chain.EthClient().BlockNumber(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_mine", hexutil.Uint64(3)))
+
+	var timestamp uint64
+	for i, chain := range chains {
+		header, err := chain.EthClient().HeaderByNumber(context.Background(), nil)
+		require.NoError(t, err)
+		require.Equal(t, startNumbers[i]+3, header.Number.Uint64(), "chain %s did not mine every block", chain.Name())
+		if i == 0 {
+			timestamp = header.Time
+		}
+		require.Equal(t, timestamp, header.Time, "chain %s is not aligned with the L1", chain.Name())
+	}
+
+	var statuses []admin.DepositRelayStatus
+	require.NoError(t, adminClient.CallContext(context.Background(), &statuses, "supersim_depositRelayStatus"))
+	require.Len(t, statuses, len(chains)-1)
+	for _, status := range statuses {
+		require.Equal(t, startNumbers[0]+3, status.RelayedL1Block)
+		require.Zero(t, status.Lag)
+	}
+
+	postBalance, err := l2Chain.EthClient().BalanceAt(context.Background(), senderAddress, nil)
+	require.NoError(t, err)
+	require.Equal(t, oneEth, new(big.Int).Sub(postBalance, prevBalance))
+}
+
+func TestL1Reorg(t *testing.T) {
+	testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{MiningMode: string(config.MiningModeNone)})
+
+	adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+	require.NoError(t, err)
+	defer adminClient.Close()
+
+	l1Chain := testSuite.Supersim.Orchestrator.L1Chain()
+	l2Chain := testSuite.Supersim.Orchestrator.L2Chains()[0]
+
+	privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+	require.NoError(t, err)
+	senderAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+	prevBalance, err := l2Chain.EthClient().BalanceAt(context.Background(), senderAddress, nil)
+	require.NoError(t, err)
+
+	oneEth := big.NewInt(1e18)
+	transactor, err := bind.NewKeyedTransactorWithChainID(privateKey, new(big.Int).SetUint64(l1Chain.ChainID()))
+	require.NoError(t, err)
+	transactor.Value = oneEth
+	transactor.GasLimit = 500000
+	optimismPortal, err := opbindings.NewOptimismPortal(common.Address(l2Chain.Config().L2Config.L1Addresses.OptimismPortalProxy), l1Chain.EthClient())
+	require.NoError(t, err)
+	_, err = optimismPortal.DepositTransaction(transactor, senderAddress, oneEth, 100000, false, make([]byte, 0))
+	require.NoError(t, err)
+
+	require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_mine", hexutil.Uint64(1)))
+	balance, err := l2Chain.EthClient().BalanceAt(context.Background(), senderAddress, nil)
+	require.NoError(t, err)
+	require.Equal(t, oneEth, new(big.Int).Sub(balance, prevBalance))
+	depositTxs := userDepositTxs(t, l2Chain)
+	require.Len(t, depositTxs, 1)
+
+	require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_mine", hexutil.Uint64(1)))
+	l1Head, err := l1Chain.EthClient().HeaderByNumber(context.Background(), nil)
+	require.NoError(t, err)
+
+	// the deposit is re-included a block later, rolled back from the L2 and re-derived from the new L1 block
+	require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_reorgL1", hexutil.Uint64(2)))
+
+	reorgedHead, err := l1Chain.EthClient().HeaderByNumber(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, l1Head.Number, reorgedHead.Number)
+	require.NotEqual(t, l1Head.Hash(), reorgedHead.Hash())
+
+	require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_mine", hexutil.Uint64(1)))
+	balance, err = l2Chain.EthClient().BalanceAt(context.Background(), senderAddress, nil)
+	require.NoError(t, err)
+	require.Equal(t, oneEth, new(big.Int).Sub(balance, prevBalance))
+
+	// re-derived with a new source hash
+	reorgedDepositTxs := userDepositTxs(t, l2Chain)
+	require.Len(t, reorgedDepositTxs, 1)
+	require.NotEqual(t, depositTxs[0], reorgedDepositTxs[0])
+}
+
+// userDepositTxs returns the hashes of the deposits in the L2 head block, excluding the L1 attributes deposit
+func userDepositTxs(t *testing.T, l2Chain config.Chain) []common.Hash {
+	block, err := l2Chain.EthClient().BlockByNumber(context.Background(), nil)
+	require.NoError(t, err)
+
+	var hashes []common.Hash
+	for _, tx := range block.Transactions() {
+		if tx.Type() == types.DepositTxType && tx.To() != nil && *tx.To() != predeploys.L1BlockAddr {
+			hashes = append(hashes, tx.Hash())
+		}
+	}
+	return hashes
+}
+
+func TestL2SafetyHeads(t *testing.T) {
+	t.Run("lag", func(t *testing.T) {
+		testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{MiningMode: string(config.MiningModeNone), L2SafeLag: 2, L2FinalizedLag: 4})
+		adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+		require.NoError(t, err)
+		defer adminClient.Close()
+
+		require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_mine", hexutil.Uint64(5)))
+
+		opSim := testSuite.Supersim.Orchestrator.L2OpSims[testSuite.Supersim.Orchestrator.L2Chains()[0].ChainID()]
+		l2Client, err := ethclient.Dial(opSim.Endpoint())
+		require.NoError(t, err)
+		defer l2Client.Close()
+		latest, err := l2Client.HeaderByNumber(context.Background(), nil)
+		require.NoError(t, err)
+		safe, err := l2Client.HeaderByNumber(context.Background(), big.NewInt(int64(rpc.SafeBlockNumber)))
+		require.NoError(t, err)
+		require.Equal(t, latest.Number.Uint64()-2, safe.Number.Uint64())
+		finalized, err := l2Client.HeaderByNumber(context.Background(), big.NewInt(int64(rpc.FinalizedBlockNumber)))
+		require.NoError(t, err)
+		require.Equal(t, latest.Number.Uint64()-4, finalized.Number.Uint64())
+	})
+
+	t.Run("l1", func(t *testing.T) {
+		testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{MiningMode: string(config.MiningModeNone), L2SafetyFollowL1: true})
+		adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+		require.NoError(t, err)
+		defer adminClient.Close()
+
+		// anvil reports the safe L1 block an epoch of 32 blocks behind the latest
+		require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_mine", hexutil.Uint64(40)))
+
+		l1Safe, err := testSuite.Supersim.Orchestrator.L1Chain().EthClient().HeaderByNumber(context.Background(), big.NewInt(int64(rpc.SafeBlockNumber)))
+		require.NoError(t, err)
+
+		opSim := testSuite.Supersim.Orchestrator.L2OpSims[testSuite.Supersim.Orchestrator.L2Chains()[0].ChainID()]
+		l2Client, err := ethclient.Dial(opSim.Endpoint())
+		require.NoError(t, err)
+		defer l2Client.Close()
+		safe, err := l2Client.HeaderByNumber(context.Background(), big.NewInt(int64(rpc.SafeBlockNumber)))
+		require.NoError(t, err)
+
+		// the safe L2 block is the last derived from the safe L1 block
+		l1Block, err := bindings.NewL1BlockInterop(opsimulator.L1BlockAddress, l2Client)
+		require.NoError(t, err)
+		l1Origin, err := l1Block.Number(&bind.CallOpts{BlockNumber: safe.Number})
+		require.NoError(t, err)
+		require.Equal(t, l1Safe.Number.Uint64(), l1Origin)
+		nextL1Origin, err := l1Block.Number(&bind.CallOpts{BlockNumber: new(big.Int).Add(safe.Number, common.Big1)})
+		require.NoError(t, err)
+		require.Greater(t, nextL1Origin, l1Origin)
+	})
+}
+
+func TestWarp(t *testing.T) {
+	const day = 24 * 60 * 60
+
+	t.Run("interval", func(t *testing.T) {
+		testSuite := createTestSuite(t)
+
+		adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+		require.NoError(t, err)
+		defer adminClient.Close()
+
+		now := uint64(time.Now().Unix())
+		require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_warp", hexutil.Uint64(day)))
+
+		chains := append([]config.Chain{testSuite.Supersim.Orchestrator.L1Chain()}, testSuite.Supersim.Orchestrator.L2Chains()...)
+		for _, chain := range chains {
+			client, err := ethclient.Dial(chain.Endpoint())
+			require.NoError(t, err)
+			waitForTimestampAfter(t, client, now+day)
+			client.Close()
+		}
+	})
+
+	t.Run("lockstep", func(t *testing.T) {
+		testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{MiningMode: string(config.MiningModeNone)})
+
+		adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+		require.NoError(t, err)
+		defer adminClient.Close()
+
+		l1Client := testSuite.Supersim.Orchestrator.L1Chain().EthClient()
+		prevHeader, err := l1Client.HeaderByNumber(context.Background(), nil)
+		require.NoError(t, err)
+
+		require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_warp", hexutil.Uint64(day)))
+		require.NoError(t, adminClient.CallContext(context.Background(), nil, "supersim_mine", hexutil.Uint64(1)))
+
+		header, err := l1Client.HeaderByNumber(context.Background(), nil)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, header.Time, prevHeader.Time+day)
+		for _, chain := range testSuite.Supersim.Orchestrator.L2Chains() {
+			l2Header, err := chain.EthClient().HeaderByNumber(context.Background(), nil)
+			require.NoError(t, err)
+			require.Equal(t, header.Time, l2Header.Time)
+		}
+	})
+}
+
 func TestDepositTxSimpleEthDeposit(t *testing.T) {
 	testSuite := createTestSuite(t)
 
@@ -285,13 +573,7 @@ func TestDependencySet(t *testing.T) {
 		l1BlockInterop, err := bindings.NewL1BlockInterop(opsimulator.L1BlockAddress, l2Client)
 		require.NoError(t, err)
 
-		// TODO: fix when we add a wait for ready on the opsim
-		time.Sleep(3 * time.Second)
-
-		depSetSize, err := l1BlockInterop.DependencySetSize(&bind.CallOpts{})
-
-		require.NoError(t, err)
-		require.Equal(t, len(opSim.L2Config.DependencySet), int(depSetSize), "Dependency set size is incorrect")
+		waitForDependencySet(t, opSim)
 
 		for _, chainID := range opSim.L2Config.DependencySet {
 			dep, err := l1BlockInterop.IsInDependencySet(&bind.CallOpts{}, big.NewInt(int64(chainID)))
@@ -301,6 +583,246 @@ func TestDependencySet(t *testing.T) {
 	}
 }
 
+func TestDependencySetChanges(t *testing.T) {
+	testSuite := createTestSuite(t)
+
+	adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+	require.NoError(t, err)
+	defer adminClient.Close()
+
+	sourceChainID := config.DefaultNetworkConfig.L2Configs[0].ChainID
+	destChainID := config.DefaultNetworkConfig.L2Configs[1].ChainID
+	sourceEthClient, err := ethclient.Dial(testSuite.Supersim.Orchestrator.L2OpSims[sourceChainID].Endpoint())
+	require.NoError(t, err)
+	defer sourceEthClient.Close()
+	destEthClient, err := ethclient.Dial(testSuite.Supersim.Orchestrator.L2OpSims[destChainID].Endpoint())
+	require.NoError(t, err)
+	defer destEthClient.Close()
+
+	waitForDependencySet(t, testSuite.Supersim.Orchestrator.L2OpSims[destChainID])
+
+	require.NoError(t, adminClient.Call(nil, "supersim_removeDependency", hexutil.Uint64(destChainID), hexutil.Uint64(sourceChainID)))
+	require.NotContains(t, testSuite.Supersim.Orchestrator.L2OpSims[destChainID].DependencySet(), sourceChainID)
+
+	l1BlockInterop, err := bindings.NewL1BlockInterop(opsimulator.L1BlockAddress, destEthClient)
+	require.NoError(t, err)
+	isDependency, err := l1BlockInterop.IsInDependencySet(&bind.CallOpts{}, new(big.Int).SetUint64(sourceChainID))
+	require.NoError(t, err)
+	require.False(t, isDependency)
+
+	privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+	require.NoError(t, err)
+	msg := sendInteropMessage(t, privateKey, sourceEthClient, sourceChainID, destEthClient, destChainID)
+
+	waitForTimestampAfter(t, destEthClient, msg.Identifier.Timestamp.Uint64())
+
+	err = destEthClient.SendTransaction(context.Background(), msg.ExecuteTx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not in the dependency set")
+
+	// the message is accepted once the source chain is added back
+	require.NoError(t, adminClient.Call(nil, "supersim_addDependency", hexutil.Uint64(destChainID), hexutil.Uint64(sourceChainID)))
+	require.Contains(t, testSuite.Supersim.Orchestrator.L2OpSims[destChainID].DependencySet(), sourceChainID)
+
+	require.NoError(t, destEthClient.SendTransaction(context.Background(), msg.ExecuteTx))
+	executeMessageTxReceipt, err := bind.WaitMined(context.Background(), destEthClient, msg.ExecuteTx)
+	require.NoError(t, err)
+	require.True(t, executeMessageTxReceipt.Status == 1, "execute message transaction failed")
+}
+
+func TestL1BlockInfoUpdates(t *testing.T) {
+	testSuite := createTestSuite(t)
+
+	l1Client, err := rpc.Dial(testSuite.Supersim.Orchestrator.L1Chain().Endpoint())
+	require.NoError(t, err)
+	defer l1Client.Close()
+
+	// advance the l1 head
+	require.NoError(t, l1Client.CallContext(context.Background(), nil, "evm_mine"))
+	l1Header, err := ethclient.NewClient(l1Client).HeaderByNumber(context.Background(), nil)
+	require.NoError(t, err)
+
+	for _, opSim := range testSuite.Supersim.Orchestrator.L2OpSims {
+		l2Client, err := ethclient.Dial(opSim.Endpoint())
+		require.NoError(t, err)
+		defer l2Client.Close()
+
+		l1BlockInterop, err := bindings.NewL1BlockInterop(opsimulator.L1BlockAddress, l2Client)
+		require.NoError(t, err)
+
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			number, err := l1BlockInterop.Number(&bind.CallOpts{})
+			assert.NoError(c, err)
+			assert.Equal(c, l1Header.Number.Uint64(), number)
+		}, 5*time.Second, 100*time.Millisecond)
+
+		hash, err := l1BlockInterop.Hash(&bind.CallOpts{})
+		require.NoError(t, err)
+		require.Equal(t, l1Header.Hash(), common.Hash(hash))
+
+		timestamp, err := l1BlockInterop.Timestamp(&bind.CallOpts{})
+		require.NoError(t, err)
+		require.Equal(t, l1Header.Time, timestamp)
+
+		basefee, err := l1BlockInterop.Basefee(&bind.CallOpts{})
+		require.NoError(t, err)
+		require.Equal(t, l1Header.BaseFee, basefee)
+	}
+}
+
+func TestAdminRPC(t *testing.T) {
+	testSuite := createTestSuite(t)
+
+	adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+	require.NoError(t, err)
+	defer adminClient.Close()
+
+	var chains []admin.ChainInfo
+	require.NoError(t, adminClient.CallContext(context.Background(), &chains, "supersim_chains"))
+	require.Len(t, chains, len(testSuite.Supersim.Orchestrator.L2OpSims)+1)
+	require.False(t, chains[0].IsL2)
+	require.Equal(t, testSuite.Supersim.Orchestrator.L1Chain().Endpoint(), chains[0].RPCUrl)
+	for _, chain := range chains[1:] {
+		opSim := testSuite.Supersim.Orchestrator.L2OpSims[chain.ChainID]
+		require.True(t, chain.IsL2)
+		require.Equal(t, opSim.Endpoint(), chain.RPCUrl)
+		require.Equal(t, opSim.L2Config.DependencySet, chain.DependencySet)
+	}
+
+	var cfg string
+	require.NoError(t, adminClient.CallContext(context.Background(), &cfg, "supersim_config"))
+	require.Equal(t, testSuite.Supersim.Orchestrator.ConfigAsString(), cfg)
+
+	var health admin.Health
+	require.NoError(t, adminClient.CallContext(context.Background(), &health, "supersim_health"))
+	require.True(t, health.Healthy)
+	require.Len(t, health.Chains, len(chains))
+
+	chainID := chains[1].ChainID
+	var addresses registry.AddressList
+	require.NoError(t, adminClient.CallContext(context.Background(), &addresses, "supersim_l1Addresses", hexutil.Uint64(chainID)))
+	require.Equal(t, *testSuite.Supersim.Orchestrator.L2OpSims[chainID].L2Config.L1Addresses, addresses)
+}
+
+func TestSupervisorRPC(t *testing.T) {
+	testSuite := createTestSuite(t)
+
+	supervisorClient, err := rpc.Dial(testSuite.Supersim.SupervisorServer.Endpoint())
+	require.NoError(t, err)
+	defer supervisorClient.Close()
+
+	sourceChainID := config.DefaultNetworkConfig.L2Configs[0].ChainID
+	destChainID := config.DefaultNetworkConfig.L2Configs[1].ChainID
+	sourceEthClient, err := ethclient.Dial(testSuite.Supersim.Orchestrator.L2OpSims[sourceChainID].Endpoint())
+	require.NoError(t, err)
+	defer sourceEthClient.Close()
+	destEthClient, err := ethclient.Dial(testSuite.Supersim.Orchestrator.L2OpSims[destChainID].Endpoint())
+	require.NoError(t, err)
+	defer destEthClient.Close()
+
+	privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+	require.NoError(t, err)
+	msg := sendInteropMessage(t, privateKey, sourceEthClient, sourceChainID, destEthClient, destChainID)
+
+	identifier := map[string]interface{}{
+		"origin":      msg.Identifier.Origin,
+		"blockNumber": hexutil.Uint64(msg.Identifier.BlockNumber.Uint64()),
+		"logIndex":    hexutil.Uint64(msg.Identifier.LogIndex.Uint64()),
+		"timestamp":   hexutil.Uint64(msg.Identifier.Timestamp.Uint64()),
+		"chainID":     hexutil.Uint64(sourceChainID),
+	}
+	payloadHash := crypto.Keccak256Hash(opsimulator.MessagePayloadBytes(msg.Log))
+
+	var safety string
+	require.NoError(t, supervisorClient.CallContext(context.Background(), &safety, "supervisor_checkMessage", identifier, payloadHash))
+	require.Equal(t, "finalized", safety)
+
+	messages := []map[string]interface{}{{"identifier": identifier, "payloadHash": payloadHash}}
+	require.NoError(t, supervisorClient.CallContext(context.Background(), nil, "supervisor_checkMessages", messages, "cross-unsafe"))
+
+	// a message with a different payload does not exist
+	messages[0]["payloadHash"] = common.Hash{}
+	err = supervisorClient.CallContext(context.Background(), nil, "supervisor_checkMessages", messages, "cross-unsafe")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "executing and initiating message fields are not equal")
+}
+
+func TestSnapshotRevert(t *testing.T) {
+	testSuite := createTestSuite(t)
+
+	adminClient, err := rpc.Dial(testSuite.Supersim.AdminServer.Endpoint())
+	require.NoError(t, err)
+	defer adminClient.Close()
+
+	var id hexutil.Uint64
+	require.NoError(t, adminClient.CallContext(context.Background(), &id, "supersim_snapshot"))
+
+	// fund an account on every chain
+	account := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	chains := append([]config.Chain{testSuite.Supersim.Orchestrator.L1Chain()}, testSuite.Supersim.Orchestrator.L2Chains()...)
+	for _, chain := range chains {
+		client, err := rpc.Dial(chain.Endpoint())
+		require.NoError(t, err)
+		require.NoError(t, client.CallContext(context.Background(), nil, "anvil_setBalance", account, (*hexutil.Big)(big.NewInt(1e18))))
+		client.Close()
+	}
+
+	var reverted bool
+	require.NoError(t, adminClient.CallContext(context.Background(), &reverted, "supersim_revert", id))
+	require.True(t, reverted)
+
+	for _, chain := range chains {
+		balance, err := chain.EthClient().BalanceAt(context.Background(), account, nil)
+		require.NoError(t, err)
+		require.Zero(t, balance.Sign(), "chain %s was not reverted", chain.Name())
+	}
+
+	// the snapshot is consumed by the revert
+	require.NoError(t, adminClient.CallContext(context.Background(), &reverted, "supersim_revert", id))
+	require.False(t, reverted)
+}
+
+func TestStateDirRestore(t *testing.T) {
+	cfg := &config.CLIConfig{StateDir: t.TempDir()}
+	account := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	balance := big.NewInt(1e18)
+
+	// first session funds the account on every chain
+	supersim, err := NewSupersim(testlog.Logger(t, log.LevelInfo), "", cfg)
+	require.NoError(t, err)
+	require.NoError(t, supersim.Start(context.Background()))
+
+	chains := append([]config.Chain{supersim.Orchestrator.L1Chain()}, supersim.Orchestrator.L2Chains()...)
+	for _, chain := range chains {
+		client, err := rpc.Dial(chain.Endpoint())
+		require.NoError(t, err)
+		require.NoError(t, client.CallContext(context.Background(), nil, "anvil_setBalance", account, (*hexutil.Big)(balance)))
+		client.Close()
+	}
+
+	l2Endpoints := make(map[uint64]string)
+	for chainID, opSim := range supersim.Orchestrator.L2OpSims {
+		l2Endpoints[chainID] = opSim.Endpoint()
+	}
+	require.NoError(t, supersim.Stop(context.Background()))
+
+	// second session restores the balances behind the same endpoints
+	supersim, err = NewSupersim(testlog.Logger(t, log.LevelInfo), "", cfg)
+	require.NoError(t, err)
+	require.NoError(t, supersim.Start(context.Background()))
+	defer func() { require.NoError(t, supersim.Stop(context.Background())) }()
+
+	chains = append([]config.Chain{supersim.Orchestrator.L1Chain()}, supersim.Orchestrator.L2Chains()...)
+	for _, chain := range chains {
+		restoredBalance, err := chain.EthClient().BalanceAt(context.Background(), account, nil)
+		require.NoError(t, err)
+		require.Equal(t, balance, restoredBalance, "chain %s was not restored", chain.Name())
+	}
+	for chainID, opSim := range supersim.Orchestrator.L2OpSims {
+		require.Equal(t, l2Endpoints[chainID], opSim.Endpoint())
+	}
+}
+
 func TestBatchJsonRpcRequests(t *testing.T) {
 	testSuite := createTestSuite(t)
 
@@ -321,7 +843,7 @@ func TestBatchJsonRpcRequests(t *testing.T) {
 	}
 }
 
-func TestInteropInvariantCheckSucceeds(t *testing.T) {
+func TestBatchJsonRpcRequestsInteropInvariantErrors(t *testing.T) {
 	testSuite := createInteropTestSuite(t)
 	gasLimit := uint64(30000000)
 	gasPrice := big.NewInt(10000000)
@@ -329,8 +851,47 @@ func TestInteropInvariantCheckSucceeds(t *testing.T) {
 	require.NoError(t, err)
 	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
-	// TODO: fix when we add a wait for ready on the opsim
-	time.Sleep(3 * time.Second)
+	// Executing message referencing an initiating message that does not exist
+	genesisBlock, err := testSuite.SourceEthClient.BlockByNumber(context.Background(), big.NewInt(0))
+	require.NoError(t, err)
+	identifier := opsimulator.MessageIdentifier{
+		Origin:      common.HexToAddress(l2toL2CrossDomainMessengerAddress),
+		BlockNumber: big.NewInt(0),
+		LogIndex:    big.NewInt(0),
+		Timestamp:   new(big.Int).SetUint64(genesisBlock.Time()),
+		ChainId:     testSuite.SourceChainID,
+	}
+	executeMessageCallData, err := opsimulator.NewCrossL2Inbox().Abi.Pack("executeMessage", identifier, fromAddress, []byte{})
+	require.NoError(t, err)
+	nonce, err := testSuite.DestEthClient.PendingNonceAt(context.Background(), fromAddress)
+	require.NoError(t, err)
+	executeMessageTx := types.NewTransaction(nonce, predeploys.CrossL2InboxAddr, big.NewInt(0), gasLimit, gasPrice, executeMessageCallData)
+	executeMessageSignedTx, err := types.SignTx(executeMessageTx, types.NewEIP155Signer(testSuite.DestChainID), privateKey)
+	require.NoError(t, err)
+	rawTx, err := executeMessageSignedTx.MarshalBinary()
+	require.NoError(t, err)
+
+	elems := []rpc.BatchElem{
+		{Method: "eth_chainId", Result: new(hexutil.Uint64)},
+		{Method: "eth_sendRawTransaction", Args: []interface{}{hexutil.Encode(rawTx)}, Result: new(common.Hash)},
+		{Method: "eth_blockNumber", Result: new(hexutil.Uint64)},
+	}
+	require.NoError(t, testSuite.DestEthClient.Client().BatchCall(elems))
+
+	require.Nil(t, elems[0].Error)
+	require.Equal(t, testSuite.DestChainID.Uint64(), uint64(*(elems[0].Result).(*hexutil.Uint64)))
+	require.Error(t, elems[1].Error)
+	require.Contains(t, elems[1].Error.Error(), "interop invariants not met")
+	require.Nil(t, elems[2].Error)
+}
+
+func TestInteropInvariantCheckSucceeds(t *testing.T) {
+	testSuite := createInteropTestSuite(t)
+	gasLimit := uint64(30000000)
+	gasPrice := big.NewInt(10000000)
+	privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+	require.NoError(t, err)
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
 	// Create initiating message using L2ToL2CrossDomainMessenger
 	origin := common.HexToAddress(l2toL2CrossDomainMessengerAddress)
@@ -373,6 +934,10 @@ func TestInteropInvariantCheckSucceeds(t *testing.T) {
 	require.NoError(t, err)
 	executeMessageSignedTx, err := types.SignTx(executeMessageTx, types.NewEIP155Signer(testSuite.DestChainID), privateKey)
 	require.NoError(t, err)
+
+	// the executing message must be included after the initiating message
+	waitForTimestampAfter(t, testSuite.DestEthClient, initiatingMessageBlock.Time())
+
 	err = testSuite.DestEthClient.SendTransaction(context.Background(), executeMessageSignedTx)
 	require.NoError(t, err)
 	executeMessageTxReceipt, err := bind.WaitMined(context.Background(), testSuite.DestEthClient, executeMessageSignedTx)
@@ -380,6 +945,100 @@ func TestInteropInvariantCheckSucceeds(t *testing.T) {
 	require.True(t, executeMessageTxReceipt.Status == 1, "execute message transaction failed")
 }
 
+func TestInteropInvariantCheckFailsExpiredMessage(t *testing.T) {
+	testSuite := createTestSuiteWithCLIConfig(t, &config.CLIConfig{InteropExpiryWindow: 1})
+	sourceChainID := config.DefaultNetworkConfig.L2Configs[0].ChainID
+	destChainID := config.DefaultNetworkConfig.L2Configs[1].ChainID
+	sourceEthClient, err := ethclient.Dial(testSuite.Supersim.Orchestrator.L2OpSims[sourceChainID].Endpoint())
+	require.NoError(t, err)
+	defer sourceEthClient.Close()
+	destEthClient, err := ethclient.Dial(testSuite.Supersim.Orchestrator.L2OpSims[destChainID].Endpoint())
+	require.NoError(t, err)
+	defer destEthClient.Close()
+
+	waitForDependencySet(t, testSuite.Supersim.Orchestrator.L2OpSims[destChainID])
+
+	privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+	require.NoError(t, err)
+	msg := sendInteropMessage(t, privateKey, sourceEthClient, sourceChainID, destEthClient, destChainID)
+
+	// wait for the message to fall outside of the expiry window
+	waitForTimestampAfter(t, destEthClient, msg.Identifier.Timestamp.Uint64()+1)
+
+	err = destEthClient.SendTransaction(context.Background(), msg.ExecuteTx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expired")
+}
+
+// interopMessage is an initiating message sent through the L2ToL2CrossDomainMessenger and the
+// signed, unsent, transaction executing it on the destination chain
+type interopMessage struct {
+	Identifier opsimulator.MessageIdentifier
+	Log        *types.Log
+	ExecuteTx  *types.Transaction
+}
+
+// sendInteropMessage sends an empty message to the destination chain, waits for it to be mined and
+// signs the CrossL2Inbox transaction executing it, leaving its submission to the caller
+func sendInteropMessage(t *testing.T, privateKey *ecdsa.PrivateKey, sourceEthClient *ethclient.Client, sourceChainID uint64, destEthClient *ethclient.Client, destChainID uint64) *interopMessage {
+	origin := common.HexToAddress(l2toL2CrossDomainMessengerAddress)
+	transactor, err := bind.NewKeyedTransactorWithChainID(privateKey, new(big.Int).SetUint64(sourceChainID))
+	require.NoError(t, err)
+	messenger := bind.NewBoundContract(origin, opsimulator.L2ToL2CrossDomainMessengerABI, sourceEthClient, sourceEthClient, sourceEthClient)
+	initiatingMsgTx, err := messenger.Transact(transactor, "sendMessage", new(big.Int).SetUint64(destChainID), predeploys.SchemaRegistryAddr, []byte{})
+	require.NoError(t, err)
+	initiatingMessageTxReceipt, err := bind.WaitMined(context.Background(), sourceEthClient, initiatingMsgTx)
+	require.NoError(t, err)
+	require.True(t, initiatingMessageTxReceipt.Status == 1, "initiating message transaction failed")
+	initiatingMessageBlock, err := sourceEthClient.BlockByNumber(context.Background(), initiatingMessageTxReceipt.BlockNumber)
+	require.NoError(t, err)
+
+	initiatingMessageLog := initiatingMessageTxReceipt.Logs[0]
+	identifier := opsimulator.MessageIdentifier{
+		Origin:      origin,
+		BlockNumber: initiatingMessageTxReceipt.BlockNumber,
+		LogIndex:    big.NewInt(int64(initiatingMessageLog.Index)),
+		Timestamp:   new(big.Int).SetUint64(initiatingMessageBlock.Time()),
+		ChainId:     new(big.Int).SetUint64(sourceChainID),
+	}
+
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+	executeMessageCallData, err := opsimulator.NewCrossL2Inbox().Abi.Pack("executeMessage", identifier, fromAddress, initiatingMessageLog.Data)
+	require.NoError(t, err)
+	nonce, err := destEthClient.PendingNonceAt(context.Background(), fromAddress)
+	require.NoError(t, err)
+	executeMessageTx := types.NewTransaction(nonce, predeploys.CrossL2InboxAddr, big.NewInt(0), 30000000, big.NewInt(10000000), executeMessageCallData)
+	executeMessageSignedTx, err := types.SignTx(executeMessageTx, types.NewEIP155Signer(new(big.Int).SetUint64(destChainID)), privateKey)
+	require.NoError(t, err)
+
+	return &interopMessage{Identifier: identifier, Log: initiatingMessageLog, ExecuteTx: executeMessageSignedTx}
+}
+
+// waitForDependencySet waits for the dependency set of the op-simulator, submitted through
+// deposits once started, to be set on its chain
+func waitForDependencySet(t *testing.T, opSim *opsimulator.OpSimulator) {
+	l2Client, err := ethclient.Dial(opSim.Endpoint())
+	require.NoError(t, err)
+	defer l2Client.Close()
+
+	l1BlockInterop, err := bindings.NewL1BlockInterop(opsimulator.L1BlockAddress, l2Client)
+	require.NoError(t, err)
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		depSetSize, err := l1BlockInterop.DependencySetSize(&bind.CallOpts{})
+		assert.NoError(c, err)
+		assert.Equal(c, len(opSim.DependencySet()), int(depSetSize), "Dependency set size is incorrect")
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+// waitForTimestampAfter waits for the chain to produce a block later than the timestamp
+func waitForTimestampAfter(t *testing.T, client *ethclient.Client, timestamp uint64) {
+	require.Eventually(t, func() bool {
+		header, err := client.HeaderByNumber(context.Background(), nil)
+		return err == nil && header.Time > timestamp
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
 func TestInteropInvariantCheckFailsBadLogIndex(t *testing.T) {
 	testSuite := createInteropTestSuite(t)
 	gasLimit := uint64(30000000)
@@ -388,9 +1047,6 @@ func TestInteropInvariantCheckFailsBadLogIndex(t *testing.T) {
 	require.NoError(t, err)
 	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
-	// TODO: fix when we add a wait for ready on the opsim
-	time.Sleep(3 * time.Second)
-
 	// Create initiating message using L2ToL2CrossDomainMessenger
 	origin := common.HexToAddress(l2toL2CrossDomainMessengerAddress)
 	initiatingMsgNonce, err := testSuite.DestEthClient.PendingNonceAt(context.Background(), fromAddress)
@@ -444,9 +1100,6 @@ func TestInteropInvariantCheckBadBlockNumber(t *testing.T) {
 	require.NoError(t, err)
 	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
-	// TODO: fix when we add a wait for ready on the opsim
-	time.Sleep(3 * time.Second)
-
 	// Create initiating message using L2ToL2CrossDomainMessenger
 	origin := common.HexToAddress(l2toL2CrossDomainMessengerAddress)
 	initiatingMsgNonce, err := testSuite.DestEthClient.PendingNonceAt(context.Background(), fromAddress)
@@ -501,9 +1154,6 @@ func TestInteropInvariantCheckBadBlockTimestamp(t *testing.T) {
 	require.NoError(t, err)
 	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
-	// TODO: fix when we add a wait for ready on the opsim
-	time.Sleep(3 * time.Second)
-
 	// Create initiating message using L2ToL2CrossDomainMessenger
 	origin := common.HexToAddress(l2toL2CrossDomainMessengerAddress)
 	initiatingMsgNonce, err := testSuite.DestEthClient.PendingNonceAt(context.Background(), fromAddress)
@@ -548,3 +1198,95 @@ func TestInteropInvariantCheckBadBlockTimestamp(t *testing.T) {
 	err = testSuite.DestEthClient.SendTransaction(context.Background(), executeMessageSignedTx)
 	require.Error(t, err)
 }
+
+func TestAutoRelayL2ToL2Message(t *testing.T) {
+	cfg := &config.CLIConfig{InteropAutoRelay: true, InteropRelayerAccount: 9}
+	testSuite := createTestSuiteWithCLIConfig(t, cfg)
+
+	sourceOpSim := testSuite.Supersim.Orchestrator.L2OpSims[config.DefaultNetworkConfig.L2Configs[0].ChainID]
+	sourceEthClient, err := ethclient.Dial(sourceOpSim.Endpoint())
+	require.NoError(t, err)
+	defer sourceEthClient.Close()
+
+	destOpSim := testSuite.Supersim.Orchestrator.L2OpSims[config.DefaultNetworkConfig.L2Configs[1].ChainID]
+	destEthClient, err := ethclient.Dial(destOpSim.Endpoint())
+	require.NoError(t, err)
+	defer destEthClient.Close()
+
+	sourceChainID := new(big.Int).SetUint64(sourceOpSim.ChainID())
+	destChainID := new(big.Int).SetUint64(destOpSim.ChainID())
+
+	waitForDependencySet(t, sourceOpSim)
+	waitForDependencySet(t, destOpSim)
+
+	privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+	require.NoError(t, err)
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	// Send a message to the destination chain
+	parsedSchemaRegistryAbi, _ := abi.JSON(strings.NewReader(opbindings.SchemaRegistryABI))
+	data, err := parsedSchemaRegistryAbi.Pack("register", "uint256 value", common.HexToAddress("0x0000000000000000000000000000000000000000"), false)
+	require.NoError(t, err)
+	sendMessage, err := opsimulator.L2ToL2CrossDomainMessengerABI.Pack("sendMessage", destChainID, predeploys.SchemaRegistryAddr, data)
+	require.NoError(t, err)
+
+	nonce, err := sourceEthClient.PendingNonceAt(context.Background(), fromAddress)
+	require.NoError(t, err)
+	tx := types.NewTransaction(nonce, opsimulator.L2ToL2CrossDomainMessengerAddress, big.NewInt(0), uint64(30000000), big.NewInt(10000000), sendMessage)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(sourceChainID), privateKey)
+	require.NoError(t, err)
+	require.NoError(t, sourceEthClient.SendTransaction(context.Background(), signedTx))
+
+	receipt, err := bind.WaitMined(context.Background(), sourceEthClient, signedTx)
+	require.NoError(t, err)
+	require.True(t, receipt.Status == 1, "initiating message transaction failed")
+
+	// The relayer should submit the executing message on the destination chain
+	relayedMessageTopic := crypto.Keccak256Hash([]byte("RelayedMessage(bytes32)"))
+	require.Eventually(t, func() bool {
+		logs, err := destEthClient.FilterLogs(context.Background(), ethereum.FilterQuery{
+			Addresses: []common.Address{opsimulator.L2ToL2CrossDomainMessengerAddress},
+			Topics:    [][]common.Hash{{relayedMessageTopic}},
+		})
+		return err == nil && len(logs) == 1
+	}, 10*time.Second, 500*time.Millisecond, "message was not relayed")
+}
+
+func TestWithdrawalAutoFinalize(t *testing.T) {
+	cfg := &config.CLIConfig{WithdrawalsAutoFinalize: true, WithdrawalsAccount: 8}
+	testSuite := createTestSuiteWithCLIConfig(t, cfg)
+
+	l1Chain := testSuite.Supersim.Orchestrator.L1Chain()
+	l1EthClient, err := ethclient.Dial(l1Chain.Endpoint())
+	require.NoError(t, err)
+	defer l1EthClient.Close()
+
+	l2Chain := testSuite.Supersim.Orchestrator.L2Chains()[0]
+	l2EthClient, err := ethclient.Dial(l2Chain.Endpoint())
+	require.NoError(t, err)
+	defer l2EthClient.Close()
+
+	privateKey, err := testSuite.HdAccountStore.DerivePrivateKeyAt(uint32(0))
+	require.NoError(t, err)
+	recipient := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	// Initiate a withdrawal of 1 ETH
+	oneEth := big.NewInt(1e18)
+	messagePasser, err := opbindings.NewL2ToL1MessagePasser(predeploys.L2ToL1MessagePasserAddr, l2EthClient)
+	require.NoError(t, err)
+	transactor, err := bind.NewKeyedTransactorWithChainID(privateKey, new(big.Int).SetUint64(l2Chain.ChainID()))
+	require.NoError(t, err)
+	transactor.Value = oneEth
+	tx, err := messagePasser.InitiateWithdrawal(transactor, recipient, big.NewInt(100000), []byte{})
+	require.NoError(t, err)
+
+	receipt, err := bind.WaitMined(context.Background(), l2EthClient, tx)
+	require.NoError(t, err)
+	require.True(t, receipt.Status == 1, "withdrawal transaction failed")
+
+	// The recipient is credited on the L1 once the withdrawal is finalized
+	require.Eventually(t, func() bool {
+		balance, err := l1EthClient.BalanceAt(context.Background(), recipient, nil)
+		return err == nil && balance.Cmp(oneEth) >= 0
+	}, 30*time.Second, 500*time.Millisecond, "withdrawal was not finalized")
+}
@@ -0,0 +1,78 @@
+package supervisor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Prefixes of the entries in the access list of a transaction executing messages. See the
+// CrossL2Inbox section of the interop specs for the encoding
+const (
+	prefixLookup           byte = 0x01
+	prefixChainIDExtension byte = 0x02
+	prefixChecksum         byte = 0x03
+)
+
+var errMalformedAccessList = errors.New("malformed access list")
+
+// access references an initiating message, committed to by the checksum
+type access struct {
+	ChainID     *big.Int
+	BlockNumber uint64
+	Timestamp   uint64
+	LogIndex    uint32
+	Checksum    common.Hash
+}
+
+// parseAccessList decodes the lookup entry, optional chain id extension and checksum of every access
+func parseAccessList(entries []common.Hash) ([]access, error) {
+	var accesses []access
+	for len(entries) > 0 {
+		lookup := entries[0]
+		if lookup[0] != prefixLookup || lookup[1] != 0 || lookup[2] != 0 || lookup[3] != 0 {
+			return nil, fmt.Errorf("%w: expected lookup entry, got %s", errMalformedAccessList, lookup)
+		}
+		entries = entries[1:]
+
+		var chainID [32]byte
+		copy(chainID[24:], lookup[4:12])
+		if len(entries) > 0 && entries[0][0] == prefixChainIDExtension {
+			copy(chainID[:24], entries[0][8:])
+			entries = entries[1:]
+		}
+
+		if len(entries) == 0 || entries[0][0] != prefixChecksum {
+			return nil, fmt.Errorf("%w: missing checksum entry", errMalformedAccessList)
+		}
+
+		accesses = append(accesses, access{
+			ChainID:     new(big.Int).SetBytes(chainID[:]),
+			BlockNumber: binary.BigEndian.Uint64(lookup[12:20]),
+			Timestamp:   binary.BigEndian.Uint64(lookup[20:28]),
+			LogIndex:    binary.BigEndian.Uint32(lookup[28:32]),
+			Checksum:    entries[0],
+		})
+		entries = entries[1:]
+	}
+	return accesses, nil
+}
+
+// checksum commits to the initiating message emitted by the origin with the payload hash
+func (a *access) checksum(origin common.Address, payloadHash common.Hash) common.Hash {
+	logHash := crypto.Keccak256(origin.Bytes(), payloadHash.Bytes())
+
+	var idPacked [32]byte
+	binary.BigEndian.PutUint64(idPacked[12:20], a.BlockNumber)
+	binary.BigEndian.PutUint64(idPacked[20:28], a.Timestamp)
+	binary.BigEndian.PutUint32(idPacked[28:32], a.LogIndex)
+	idLogHash := crypto.Keccak256(logHash, idPacked[:])
+
+	checksum := crypto.Keccak256Hash(idLogHash, common.BigToHash(a.ChainID).Bytes())
+	checksum[0] = prefixChecksum
+	return checksum
+}
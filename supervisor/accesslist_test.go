@@ -0,0 +1,54 @@
+package supervisor
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccessList(t *testing.T) {
+	lookup := common.HexToHash("0x01000000" + "0000000000000385" + "000000000000002a" + "0000000066b0c8f0" + "00000003")
+	checksum := common.HexToHash("0x03" + strings.Repeat("11", 31))
+
+	accesses, err := parseAccessList([]common.Hash{lookup, checksum})
+	require.NoError(t, err)
+	require.Len(t, accesses, 1)
+	require.Equal(t, uint64(901), accesses[0].ChainID.Uint64())
+	require.Equal(t, uint64(42), accesses[0].BlockNumber)
+	require.Equal(t, uint64(0x66b0c8f0), accesses[0].Timestamp)
+	require.Equal(t, uint32(3), accesses[0].LogIndex)
+	require.Equal(t, checksum, accesses[0].Checksum)
+
+	// the upper bytes of the chain id are set by the extension entry
+	extension := common.HexToHash("0x02" + "00000000000000" + "000000000000000000000000000000000000000000000001")
+	accesses, err = parseAccessList([]common.Hash{lookup, extension, checksum, lookup, checksum})
+	require.NoError(t, err)
+	require.Len(t, accesses, 2)
+	require.Equal(t, new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(901)), accesses[0].ChainID)
+	require.Equal(t, uint64(901), accesses[1].ChainID.Uint64())
+
+	_, err = parseAccessList([]common.Hash{lookup})
+	require.ErrorIs(t, err, errMalformedAccessList)
+
+	_, err = parseAccessList([]common.Hash{checksum})
+	require.ErrorIs(t, err, errMalformedAccessList)
+}
+
+func TestAccessChecksum(t *testing.T) {
+	a := access{ChainID: big.NewInt(901), BlockNumber: 42, Timestamp: 1000, LogIndex: 3}
+	origin := common.HexToAddress("0x4200000000000000000000000000000000000023")
+	payloadHash := common.HexToHash("0xdeadbeef")
+
+	checksum := a.checksum(origin, payloadHash)
+	require.Equal(t, prefixChecksum, checksum[0])
+
+	// every field of the identifier is committed to
+	other := a
+	other.LogIndex = 4
+	require.NotEqual(t, checksum, other.checksum(origin, payloadHash))
+	require.NotEqual(t, checksum, a.checksum(common.Address{}, payloadHash))
+}
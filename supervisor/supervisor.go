@@ -0,0 +1,243 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	ophttp "github.com/ethereum-optimism/optimism/op-service/httputil"
+	"github.com/ethereum-optimism/optimism/op-supervisor/supervisor/types"
+	"github.com/ethereum-optimism/supersim/config"
+	"github.com/ethereum-optimism/supersim/opsimulator"
+	"github.com/ethereum-optimism/supersim/orchestrator"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	host = "127.0.0.1"
+
+	// JSON-RPC namespace of the op-supervisor query methods
+	Namespace = "supervisor"
+
+	// The chains are not derived from batches on the L1 and are never reorged, so
+	// any valid message is reported at the highest safety level
+	messageSafety = types.Finalized
+)
+
+// SupervisorServer is a stand-in for the op-supervisor RPC, validating messages
+// across the chains managed by the Orchestrator
+type SupervisorServer struct {
+	log log.Logger
+
+	orchestrator *orchestrator.Orchestrator
+
+	port       uint64
+	rpcServer  *rpc.Server
+	httpServer *ophttp.HTTPServer
+
+	stopped atomic.Bool
+}
+
+type supervisorAPI struct {
+	log          log.Logger
+	orchestrator *orchestrator.Orchestrator
+}
+
+// Message references an initiating message and the hash of its payload
+type Message struct {
+	Identifier  types.Identifier `json:"identifier"`
+	PayloadHash common.Hash      `json:"payloadHash"`
+}
+
+// ExecutingDescriptor describes the context messages are executed in. When the chain id is
+// set, the messages must also be within the dependency set of that chain
+type ExecutingDescriptor struct {
+	Timestamp hexutil.Uint64  `json:"timestamp"`
+	Timeout   *hexutil.Uint64 `json:"timeout,omitempty"`
+	ChainID   *hexutil.Big    `json:"chainID,omitempty"`
+}
+
+func NewSupervisorServer(log log.Logger, port uint64, orchestrator *orchestrator.Orchestrator) *SupervisorServer {
+	return &SupervisorServer{log: log, port: port, orchestrator: orchestrator}
+}
+
+func (s *SupervisorServer) Start(ctx context.Context) error {
+	s.rpcServer = rpc.NewServer()
+	if err := s.rpcServer.RegisterName(Namespace, &supervisorAPI{s.log, s.orchestrator}); err != nil {
+		return fmt.Errorf("failed to register supervisor api: %w", err)
+	}
+
+	hs, err := ophttp.StartHTTPServer(net.JoinHostPort(host, fmt.Sprintf("%d", s.port)), s.rpcServer)
+	if err != nil {
+		return fmt.Errorf("failed to start supervisor HTTP RPC server: %w", err)
+	}
+
+	s.log.Debug("started supervisor server", "addr", hs.Addr())
+	s.httpServer = hs
+
+	if s.port == 0 {
+		s.port, err = strconv.ParseUint(strings.Split(hs.Addr().String(), ":")[1], 10, 64)
+		if err != nil {
+			panic(fmt.Errorf("unexpected supervisor server listening port: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func (s *SupervisorServer) Stop(ctx context.Context) error {
+	if s.stopped.Load() {
+		return errors.New("already stopped")
+	}
+	if !s.stopped.CompareAndSwap(false, true) {
+		return nil // someone else stopped
+	}
+	if s.httpServer == nil {
+		return nil // never started
+	}
+
+	s.rpcServer.Stop()
+	return s.httpServer.Stop(ctx)
+}
+
+func (s *SupervisorServer) Stopped() bool {
+	return s.stopped.Load()
+}
+
+func (s *SupervisorServer) Endpoint() string {
+	return fmt.Sprintf("http://%s:%d", host, s.port)
+}
+
+// CheckMessage returns the safety level of the initiating message
+func (api *supervisorAPI) CheckMessage(ctx context.Context, identifier types.Identifier, payloadHash common.Hash) (types.SafetyLevel, error) {
+	if err := api.checkInitiatingMessage(ctx, toMessageIdentifier(identifier), payloadHash); err != nil {
+		return "", err
+	}
+	return messageSafety, nil
+}
+
+// CheckMessages validates that every initiating message exists with at least the safety level
+func (api *supervisorAPI) CheckMessages(ctx context.Context, messages []Message, minSafety types.SafetyLevel) error {
+	if !minSafety.Valid() {
+		return fmt.Errorf("unrecognized safety level: %q", minSafety)
+	}
+
+	for i, msg := range messages {
+		if err := api.checkInitiatingMessage(ctx, toMessageIdentifier(msg.Identifier), msg.PayloadHash); err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// CheckAccessList validates the messages referenced by the access list of a transaction
+// executing them in the context of the descriptor
+func (api *supervisorAPI) CheckAccessList(ctx context.Context, inboxEntries []common.Hash, minSafety types.SafetyLevel, executingDescriptor ExecutingDescriptor) error {
+	if !minSafety.Valid() {
+		return fmt.Errorf("unrecognized safety level: %q", minSafety)
+	}
+
+	accesses, err := parseAccessList(inboxEntries)
+	if err != nil {
+		return err
+	}
+
+	var destOpSim *opsimulator.OpSimulator
+	if executingDescriptor.ChainID != nil {
+		chainID := executingDescriptor.ChainID.ToInt()
+		opSim, ok := api.orchestrator.L2OpSims[chainID.Uint64()]
+		if !chainID.IsUint64() || !ok {
+			return fmt.Errorf("no l2 chain with chain id %d", chainID)
+		}
+		destOpSim = opSim
+	}
+
+	executingTimestamp := uint64(executingDescriptor.Timestamp)
+	for i, access := range accesses {
+		if err := api.checkAccess(ctx, &access, destOpSim, executingTimestamp, executingDescriptor.Timeout); err != nil {
+			return fmt.Errorf("access %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (api *supervisorAPI) checkAccess(ctx context.Context, access *access, destOpSim *opsimulator.OpSimulator, executingTimestamp uint64, timeout *hexutil.Uint64) error {
+	sourceChain, sourceOpSim, err := api.sourceChain(access.ChainID)
+	if err != nil {
+		return err
+	}
+
+	blockNumber := new(big.Int).SetUint64(access.BlockNumber)
+	log, err := opsimulator.InitiatingMessageLog(ctx, sourceChain, blockNumber, uint64(access.LogIndex))
+	if err != nil {
+		return err
+	}
+
+	payloadHash := crypto.Keccak256Hash(opsimulator.MessagePayloadBytes(log))
+	if access.checksum(log.Address, payloadHash) != access.Checksum {
+		return errors.New("checksum does not match the initiating message")
+	}
+
+	id := opsimulator.MessageIdentifier{
+		Origin:      log.Address,
+		BlockNumber: blockNumber,
+		LogIndex:    new(big.Int).SetUint64(uint64(access.LogIndex)),
+		Timestamp:   new(big.Int).SetUint64(access.Timestamp),
+		ChainId:     access.ChainID,
+	}
+
+	if destOpSim != nil {
+		if err := destOpSim.CheckExecutingMessage(ctx, id, payloadHash, executingTimestamp); err != nil {
+			return err
+		}
+	} else {
+		if err := opsimulator.CheckInitiatingMessage(ctx, sourceChain, id, payloadHash); err != nil {
+			return err
+		}
+		if err := opsimulator.CheckMessageTiming(access.Timestamp, executingTimestamp, sourceOpSim.MessageExpiryWindow()); err != nil {
+			return err
+		}
+	}
+
+	// the message must remain executable until the timeout elapses
+	if timeout != nil {
+		return opsimulator.CheckMessageTiming(access.Timestamp, executingTimestamp+uint64(*timeout), sourceOpSim.MessageExpiryWindow())
+	}
+	return nil
+}
+
+func (api *supervisorAPI) checkInitiatingMessage(ctx context.Context, id opsimulator.MessageIdentifier, payloadHash common.Hash) error {
+	sourceChain, _, err := api.sourceChain(id.ChainId)
+	if err != nil {
+		return err
+	}
+	return opsimulator.CheckInitiatingMessage(ctx, sourceChain, id, payloadHash)
+}
+
+func (api *supervisorAPI) sourceChain(chainID *big.Int) (config.Chain, *opsimulator.OpSimulator, error) {
+	opSim, ok := api.orchestrator.L2OpSims[chainID.Uint64()]
+	if !chainID.IsUint64() || !ok {
+		return nil, nil, fmt.Errorf("no chain found for chain id: %d", chainID)
+	}
+	return api.orchestrator.L2Chain(chainID.Uint64()), opSim, nil
+}
+
+func toMessageIdentifier(identifier types.Identifier) opsimulator.MessageIdentifier {
+	return opsimulator.MessageIdentifier{
+		Origin:      identifier.Origin,
+		BlockNumber: new(big.Int).SetUint64(identifier.BlockNumber),
+		LogIndex:    new(big.Int).SetUint64(identifier.LogIndex),
+		Timestamp:   new(big.Int).SetUint64(identifier.Timestamp),
+		ChainId:     identifier.ChainID.ToBig(),
+	}
+}
@@ -22,7 +22,17 @@ func (s *MockSubscription) Err() <-chan error {
 	return make(<-chan error)
 }
 
+// MockChain is a chain without state. Responses can be overridden through the fields
 type MockChain struct {
+	// Returned by WSEndpoint when set
+	WSEndpointUrl string
+
+	// Returned by EthGetLogs regardless of the query
+	Logs []types.Log
+
+	// Returned by Config and EthClient
+	ChainConfig *config.ChainConfig
+	Client      *ethclient.Client
 }
 
 func NewMockChain() *MockChain {
@@ -41,16 +51,23 @@ func (c *MockChain) Endpoint() string {
 	return "http://localhost:8545"
 }
 
+func (c *MockChain) WSEndpoint() string {
+	if c.WSEndpointUrl != "" {
+		return c.WSEndpointUrl
+	}
+	return "ws://localhost:8545"
+}
+
 func (c *MockChain) LogPath() string {
 	return "var/chain/log"
 }
 
 func (c *MockChain) Config() *config.ChainConfig {
-	return nil
+	return c.ChainConfig
 }
 
 func (c *MockChain) EthClient() *ethclient.Client {
-	return nil
+	return c.Client
 }
 
 func (c *MockChain) EthGetCode(ctx context.Context, account common.Address) ([]byte, error) {
@@ -58,6 +75,9 @@ func (c *MockChain) EthGetCode(ctx context.Context, account common.Address) ([]b
 }
 
 func (c *MockChain) EthGetLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if c.Logs != nil {
+		return c.Logs, nil
+	}
 	return []types.Log{}, nil
 }
 